@@ -7,16 +7,18 @@
 package athena
 
 import (
-	"crypto/tls"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"log"
+	"math/rand"
 	"net/http"
+	"net/url"
 	"path"
-	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -28,21 +30,54 @@ const WorkspaceResourceType = "workspaces"
 const ModuleEndpointResourceType = "endpoints"
 const ModulePolicyResourceType = "modulePolicies"
 const ModuleDepoloymentResourceType = "moduleManagedObjects"
+const CustomNameResourceType = "customNames"
+const NamingPolicyResourceType = "namingPolicies"
+const MicrosoftADPolicyResourceType = "microsoftADPolicies"
+const MicrosoftADComputerAccountResourceType = "microsoftADComputerAccounts"
+const DNSPolicyResourceType = "dnsPolicies"
+const DNSReservationResourceType = "dnsReservations"
+const ScriptingPolicyResourceType = "scriptingPolicies"
+const ScriptingDeploymentResourceType = "scriptingDeployments"
 const IPAMReservationResourceType = "ipamReservations"
 const IPAMPolicyResourceType = "ipamPolicies"
 const JobStatusResourceType = "jobStatus"
 const RenderTemplateType = "templateTester"
+const SessionLoginResourceType = "login"
 const JobSuccess = "Successful"
 const JobFailed = "Failed"
+const DefaultJobTimeout = 60 * time.Minute
+const DefaultPageSize = 100
 
 type AthenaAPIClient struct {
-	config *Config
+	config     *Config
+	httpClient *http.Client
+
+	// defaultWorkspaceURL is the fallback workspace for objects created without
+	// one, resolved once and then reused.
+	workspaceMu         sync.Mutex
+	defaultWorkspaceURL string
+}
+
+// namedEntity is implemented by API objects that can be looked up by name.
+type namedEntity interface {
+	entityName() string
+	entityID() int
 }
 
 type CustomName struct {
-	Id        int
-	Name      string
-	DnsSuffix string
+	Links *struct {
+		Self        LinkRef `json:"self,omitempty"`
+		Workspace   LinkRef `json:"workspace,omitempty"`
+		Policy      LinkRef `json:"policy,omitempty"`
+		JobMetadata LinkRef `json:"jobMetadata,omitempty"`
+	} `json:"_links,omitempty"`
+	Id                 int                    `json:"id,omitempty"`
+	Name               string                 `json:"name,omitempty"`
+	DnsSuffix          string                 `json:"dnsSuffix,omitempty"`
+	PolicyID           int                    `json:"policyId,omitempty"`
+	Policy             string                 `json:"policy,omitempty"`
+	WorkspaceURL       string                 `json:"workspace,omitempty"`
+	TemplateProperties map[string]interface{} `json:"template_properties,omitempty"`
 }
 
 type LinkRef struct {
@@ -54,16 +89,21 @@ type Workspace struct {
 	Links *struct {
 		Self LinkRef `json:"self,omitempty"`
 	} `json:"_links,omitempty"`
-	ID   int    `json:"id,omitempty"`
-	Name string `json:"name,omitempty"`
+	ID          int    `json:"id,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
 }
 
-type WorkspacesListResponse struct {
-	Embedded struct {
-		Workspaces []Workspace `json:"workspaces"`
-	} `json:"_embedded"`
+// workspaceUpdateRequest is the PUT body for a workspace. Unlike Workspace it
+// always sends description, so clearing it in config clears it on the server.
+type workspaceUpdateRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
 }
 
+func (w Workspace) entityName() string { return w.Name }
+func (w Workspace) entityID() int      { return w.ID }
+
 type IPAMReservation struct {
 	Links *struct {
 		Self        LinkRef `json:"self,omitempty"`
@@ -88,13 +128,69 @@ type IPAMReservation struct {
 	TemplateProperties map[string]interface{} `json:"template_properties,omitempty"`
 }
 
-type IPAMPolicyResponse struct {
-	Embedded struct {
-		IPAMPolicies []IPAMPolicy `json:"ipamPolicies"`
-	} `json:"_embedded"`
+type IPAMPolicy struct {
+	Links *struct {
+		Self      LinkRef `json:"self,omitempty"`
+		Workspace LinkRef `json:"workspace,omitempty"`
+	} `json:"_links,omitempty"`
+	ID                        int      `json:"id,omitempty"`
+	Name                      string   `json:"name,omitempty"`
+	Description               string   `json:"description,omitempty"`
+	HostnameOverride          string   `json:"hostnameOverride,omitempty"`
+	Networks                  []string `json:"networks,omitempty"`
+	Gateway                   string   `json:"gateway,omitempty"`
+	PrimaryDNS                string   `json:"primaryDns,omitempty"`
+	SecondaryDNS              string   `json:"secondaryDns,omitempty"`
+	DNSSuffix                 string   `json:"dnsSuffix,omitempty"`
+	DNSSearchSuffixes         []string `json:"dnsSearchSuffixes,omitempty"`
+	NicLabel                  string   `json:"nicLabel,omitempty"`
+	UpdateConflictNameWithDNS bool     `json:"updateConflictNameWithDns,omitempty"`
 }
 
-type IPAMPolicy struct {
+func (r IPAMReservation) entityName() string { return r.Hostname }
+func (r IPAMReservation) entityID() int      { return r.ID }
+
+func (p IPAMPolicy) entityName() string { return p.Name }
+func (p IPAMPolicy) entityID() int      { return p.ID }
+
+type MicrosoftADPolicy struct {
+	Links *struct {
+		Self      LinkRef `json:"self,omitempty"`
+		Workspace LinkRef `json:"workspace,omitempty"`
+	} `json:"_links,omitempty"`
+	ID                     int      `json:"id,omitempty"`
+	Name                   string   `json:"name,omitempty"`
+	Description            string   `json:"description,omitempty"`
+	ComputerNameLetterCase string   `json:"computerNameLetterCase,omitempty"`
+	OU                     string   `json:"ou,omitempty"`
+	CreateOU               bool     `json:"createOU,omitempty"`
+	RemoveOU               bool     `json:"removeOU,omitempty"`
+	SecurityGroups         []string `json:"securityGroups,omitempty"`
+}
+
+func (p MicrosoftADPolicy) entityName() string { return p.Name }
+func (p MicrosoftADPolicy) entityID() int      { return p.ID }
+
+type MicrosoftADComputerAccount struct {
+	Links *struct {
+		Self        LinkRef `json:"self,omitempty"`
+		Workspace   LinkRef `json:"workspace,omitempty"`
+		Policy      LinkRef `json:"policy,omitempty"`
+		JobMetadata LinkRef `json:"jobMetadata,omitempty"`
+	} `json:"_links,omitempty"`
+	ID                 int                    `json:"id,omitempty"`
+	Name               string                 `json:"name,omitempty"`
+	PolicyID           int                    `json:"policyId,omitempty"`
+	Policy             string                 `json:"policy,omitempty"`
+	WorkspaceURL       string                 `json:"workspace,omitempty"`
+	BuildOU            string                 `json:"buildOu,omitempty"`
+	FinalOU            string                 `json:"finalOu,omitempty"`
+	State              string                 `json:"state,omitempty"`
+	SecurityGroups     []string               `json:"securityGroups,omitempty"`
+	TemplateProperties map[string]interface{} `json:"template_properties,omitempty"`
+}
+
+type DNSPolicy struct {
 	Links *struct {
 		Self      LinkRef `json:"self,omitempty"`
 		Workspace LinkRef `json:"workspace,omitempty"`
@@ -104,6 +200,118 @@ type IPAMPolicy struct {
 	Description string `json:"description,omitempty"`
 }
 
+func (p DNSPolicy) entityName() string { return p.Name }
+func (p DNSPolicy) entityID() int      { return p.ID }
+
+type DNSRecord struct {
+	Type  string `json:"type,omitempty"`
+	Name  string `json:"name,omitempty"`
+	Value string `json:"value,omitempty"`
+}
+
+type DNSReservation struct {
+	Links *struct {
+		Self        LinkRef `json:"self,omitempty"`
+		Workspace   LinkRef `json:"workspace,omitempty"`
+		Policy      LinkRef `json:"policy,omitempty"`
+		JobMetadata LinkRef `json:"jobMetadata,omitempty"`
+	} `json:"_links,omitempty"`
+	ID                 int                    `json:"id,omitempty"`
+	Name               string                 `json:"name,omitempty"`
+	PolicyID           int                    `json:"policyId,omitempty"`
+	Policy             string                 `json:"policy,omitempty"`
+	WorkspaceURL       string                 `json:"workspace,omitempty"`
+	Value              string                 `json:"value,omitempty"`
+	Zones              []string               `json:"zones,omitempty"`
+	Records            []DNSRecord            `json:"records,omitempty"`
+	TemplateProperties map[string]interface{} `json:"template_properties,omitempty"`
+}
+
+type ModuleEndpoint struct {
+	Links *struct {
+		Self       LinkRef `json:"self,omitempty"`
+		Workspace  LinkRef `json:"workspace,omitempty"`
+		Credential LinkRef `json:"credential,omitempty"`
+	} `json:"_links,omitempty"`
+	ID          int    `json:"id,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Host        string `json:"host,omitempty"`
+	Port        int    `json:"port,omitempty"`
+	SSL         bool   `json:"ssl,omitempty"`
+}
+
+func (e ModuleEndpoint) entityName() string { return e.Name }
+func (e ModuleEndpoint) entityID() int      { return e.ID }
+
+type ModulePolicy struct {
+	Links *struct {
+		Self      LinkRef `json:"self,omitempty"`
+		Workspace LinkRef `json:"workspace,omitempty"`
+		Endpoint  LinkRef `json:"endpoint,omitempty"`
+	} `json:"_links,omitempty"`
+	ID          int    `json:"id,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+func (p ModulePolicy) entityName() string { return p.Name }
+func (p ModulePolicy) entityID() int      { return p.ID }
+
+type ModuleDeployment struct {
+	Links *struct {
+		Self        LinkRef `json:"self,omitempty"`
+		Workspace   LinkRef `json:"workspace,omitempty"`
+		Policy      LinkRef `json:"policy,omitempty"`
+		JobMetadata LinkRef `json:"jobMetadata,omitempty"`
+	} `json:"_links,omitempty"`
+	ID                     int                    `json:"id,omitempty"`
+	Name                   string                 `json:"name,omitempty"`
+	PolicyID               int                    `json:"policyId,omitempty"`
+	Policy                 string                 `json:"policy,omitempty"`
+	WorkspaceURL           string                 `json:"workspace,omitempty"`
+	ProvisioningJobResults json.RawMessage        `json:"provisioningJobResults,omitempty"`
+	TemplateProperties     map[string]interface{} `json:"template_properties,omitempty"`
+}
+
+type ScriptingPolicy struct {
+	Links *struct {
+		Self      LinkRef `json:"self,omitempty"`
+		Workspace LinkRef `json:"workspace,omitempty"`
+	} `json:"_links,omitempty"`
+	ID          int    `json:"id,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+func (p ScriptingPolicy) entityName() string { return p.Name }
+func (p ScriptingPolicy) entityID() int      { return p.ID }
+
+type ScriptingDetails struct {
+	Status   string `json:"status,omitempty"`
+	Stdout   string `json:"stdout,omitempty"`
+	Stderr   string `json:"stderr,omitempty"`
+	ExitCode int    `json:"exitCode,omitempty"`
+}
+
+type ScriptingDeployment struct {
+	Links *struct {
+		Self        LinkRef `json:"self,omitempty"`
+		Workspace   LinkRef `json:"workspace,omitempty"`
+		Policy      LinkRef `json:"policy,omitempty"`
+		JobMetadata LinkRef `json:"jobMetadata,omitempty"`
+	} `json:"_links,omitempty"`
+	ID                    int                    `json:"id,omitempty"`
+	Hostname              string                 `json:"hostname,omitempty"`
+	PolicyID              int                    `json:"policyId,omitempty"`
+	Policy                string                 `json:"policy,omitempty"`
+	WorkspaceURL          string                 `json:"workspace,omitempty"`
+	ProvisioningDetails   *ScriptingDetails      `json:"provisioningDetails,omitempty"`
+	DeprovisioningDetails *ScriptingDetails      `json:"deprovisioningDetails,omitempty"`
+	TemplateProperties    map[string]interface{} `json:"template_properties,omitempty"`
+}
+
 type JobStatus struct {
 	Links *struct {
 		Self          LinkRef `json:"self,omitempty"`
@@ -112,17 +320,19 @@ type JobStatus struct {
 		Policy        LinkRef `json:"policy,omitempty"`
 		Workspace     LinkRef `json:"workspace,omitempty"`
 	} `json:"_links,omitempty"`
-	ID                  int    `json:"id,omitempty"`
-	JobStateDescription string `json:"jobStateDescription,omitempty"`
-	JobState            string `json:"jobState,omitempty"`
-	JobTrackingID       string `json:"jobTrackingId,omitempty"`
-	JobType             string `json:"jobType,omitempty"`
-	ErrorDetails        *struct {
-		Code   int `json:"code,omitempty"`
-		Errors *[]struct {
-			Message string `json:"message,omitempty"`
-		} `json:"errors,omitempty"`
-	} `json:"errorDetails,omitempty"`
+	ID                  int           `json:"id,omitempty"`
+	JobStateDescription string        `json:"jobStateDescription,omitempty"`
+	JobState            string        `json:"jobState,omitempty"`
+	JobTrackingID       string        `json:"jobTrackingId,omitempty"`
+	JobType             string        `json:"jobType,omitempty"`
+	ErrorDetails        *ErrorDetails `json:"errorDetails,omitempty"`
+}
+
+type ErrorDetails struct {
+	Code   int `json:"code,omitempty"`
+	Errors *[]struct {
+		Message string `json:"message,omitempty"`
+	} `json:"errors,omitempty"`
 }
 
 type RenderTemplateRequest struct {
@@ -130,13 +340,23 @@ type RenderTemplateRequest struct {
 	TemplateProperties map[string]interface{} `json:"template_properties,omitempty"`
 }
 
-func (c *Config) NewAthenaApiClient() *AthenaAPIClient {
-	return &AthenaAPIClient{
-		config: c,
+type RenderTemplateResponse struct {
+	Value string `json:"value"`
+}
+
+func (c *Config) NewAthenaApiClient() (*AthenaAPIClient, error) {
+	httpClient, err := newHttpClient(c)
+	if err != nil {
+		return nil, err
 	}
+
+	return &AthenaAPIClient{
+		config:     c,
+		httpClient: httpClient,
+	}, nil
 }
 
-func buildPostRequest(config *Config, resourceType string, requestEntity interface{}) (*http.Request, error) {
+func buildPostRequest(ctx context.Context, config *Config, resourceType string, requestEntity interface{}) (*http.Request, error) {
 	url := collectionURL(config, resourceType)
 
 	jsonBytes, err := json.Marshal(requestEntity)
@@ -147,7 +367,7 @@ func buildPostRequest(config *Config, resourceType string, requestEntity interfa
 	requestBody := string(jsonBytes)
 	payload := strings.NewReader(requestBody)
 
-	req, err := http.NewRequest("POST", url, payload)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, payload)
 	if err != nil {
 		return nil, errors.WithMessage(err, fmt.Sprintf("athena.apiClient: Unable to create request POST %s %s", url, requestBody))
 	}
@@ -157,7 +377,7 @@ func buildPostRequest(config *Config, resourceType string, requestEntity interfa
 	return req, nil
 }
 
-func buildPutRequest(config *Config, resourceType string, requestEntity interface{}, id int) (*http.Request, error) {
+func buildPutRequest(ctx context.Context, config *Config, resourceType string, requestEntity interface{}, id int) (*http.Request, error) {
 	url := itemURL(config, resourceType, id)
 
 	jsonBytes, err := json.Marshal(requestEntity)
@@ -168,7 +388,7 @@ func buildPutRequest(config *Config, resourceType string, requestEntity interfac
 	requestBody := string(jsonBytes)
 	payload := strings.NewReader(requestBody)
 
-	req, err := http.NewRequest("PUT", url, payload)
+	req, err := http.NewRequestWithContext(ctx, "PUT", url, payload)
 	if err != nil {
 		return nil, errors.WithMessage(err, fmt.Sprintf("athena.apiClient: Unable to create request PUT %s %s", url, requestBody))
 	}
@@ -178,15 +398,28 @@ func buildPutRequest(config *Config, resourceType string, requestEntity interfac
 	return req, nil
 }
 
+func buildDeleteRequest(ctx context.Context, config *Config, resourceType string, id int) (*http.Request, error) {
+	url := itemURL(config, resourceType, id)
+
+	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
+	if err != nil {
+		return nil, errors.WithMessage(err, fmt.Sprintf("athena.apiClient: Failed to create request DELETE %s", url))
+	}
+
+	setHeaders(req, config)
+
+	return req, nil
+}
+
 //Create IPAM Reservation
 
-func (apiClient *AthenaAPIClient) CreateIPAMReservation(newIPAMRecord *IPAMReservation) (*IPAMReservation, error) {
+func (apiClient *AthenaAPIClient) CreateIPAMReservation(ctx context.Context, newIPAMRecord *IPAMReservation) (*IPAMReservation, error) {
 	log.Println("athena.apiClient: CreateIPAMReservation")
 
 	config := apiClient.config
 
 	var err error
-	if newIPAMRecord.WorkspaceURL, err = findWorkspaceURLOrDefault(config, newIPAMRecord.WorkspaceURL); err != nil {
+	if newIPAMRecord.WorkspaceURL, err = apiClient.findWorkspaceURLOrDefault(ctx, newIPAMRecord.WorkspaceURL); err != nil {
 		return nil, err
 	}
 
@@ -201,97 +434,493 @@ func (apiClient *AthenaAPIClient) CreateIPAMReservation(newIPAMRecord *IPAMReser
 	}
 
 	var req *http.Request
-	if req, err = buildPostRequest(config, IPAMReservationResourceType, newIPAMRecord); err != nil {
+	if req, err = buildPostRequest(ctx, config, IPAMReservationResourceType, newIPAMRecord); err != nil {
+		return nil, err
+	}
+
+	ipamRecord := IPAMReservation{}
+
+	_, err = apiClient.handleAsyncRequestAndFetchManagdObject(ctx, req, &ipamRecord, "POST")
+	if err != nil {
+		return nil, err
+	}
+	return &ipamRecord, nil
+}
+
+//Get IPAM Reservation
+
+func (apiClient *AthenaAPIClient) GetIPAMReservation(ctx context.Context, id int) (*IPAMReservation, error) {
+	log.Println("athena.apiClient: GetIPAMReservation")
+
+	config := apiClient.config
+
+	url := itemURL(config, IPAMReservationResourceType, id)
+
+	ipamRecord := IPAMReservation{}
+	err := apiClient.doGet(ctx, url, &ipamRecord)
+	if err != nil {
+		return nil, err
+	}
+	return &ipamRecord, err
+}
+
+//Update IPAM Record
+
+func (apiClient *AthenaAPIClient) UpdateIPAMReservation(ctx context.Context, id int, updatedIPAMReservation *IPAMReservation) (*IPAMReservation, error) {
+	log.Println("athena.apiClient: UpdateIPAMReservation")
+
+	config := apiClient.config
+
+	var err error
+	if updatedIPAMReservation.WorkspaceURL, err = apiClient.findWorkspaceURLOrDefault(ctx, updatedIPAMReservation.WorkspaceURL); err != nil {
+		return nil, err
+	}
+
+	if updatedIPAMReservation.Policy == "" {
+		if updatedIPAMReservation.PolicyID != 0 {
+			updatedIPAMReservation.Policy = itemURL(config, IPAMPolicyResourceType, updatedIPAMReservation.PolicyID)
+		} else {
+			return nil, errors.New("athena.apiClient: IPAM Record Update requires a PolicyID or Policy URL")
+		}
+	}
+
+	var req *http.Request
+	if req, err = buildPutRequest(ctx, config, IPAMReservationResourceType, updatedIPAMReservation, id); err != nil {
+		return nil, err
+	}
+
+	ipamRecord := IPAMReservation{}
+
+	_, err = apiClient.handleAsyncRequestAndFetchManagdObject(ctx, req, &ipamRecord, "PUT")
+	if err != nil {
+		return nil, err
+	}
+	return &ipamRecord, nil
+}
+
+func (apiClient *AthenaAPIClient) DeleteIPAMReservation(ctx context.Context, id int) error {
+	log.Println("athena.apiClient: DeleteIPAMReservation")
+
+	config := apiClient.config
+
+	url := itemURL(config, IPAMReservationResourceType, id)
+
+	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
+	if err != nil {
+		return errors.WithMessage(err, fmt.Sprintf("athena.apiClient: Failed to create request DELETE %s", url))
+	}
+
+	setHeaders(req, config)
+
+	_, err = apiClient.handleAsyncRequest(ctx, req, "DELETE")
+	return err
+}
+
+func (apiClient *AthenaAPIClient) GetIPAMReservationByHostname(ctx context.Context, hostname string, policyID int) (*IPAMReservation, error) {
+	log.Println("athena.apiClient: GetIPAMReservationByHostname")
+
+	query := CollectionQuery{Filters: []string{"hostname.exact:" + hostname}}
+
+	ipamReservations, err := List[IPAMReservation](ctx, apiClient, IPAMReservationResourceType, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []IPAMReservation
+	for _, ipamRecord := range ipamReservations {
+		// The policy is only known through the reservation's links, so a
+		// reservation returned without them can't belong to policyID.
+		if ipamRecord.Links == nil {
+			continue
+		}
+		if ipamRecord.Hostname == hostname && idFromHref(ipamRecord.Links.Policy.Href) == policyID {
+			matches = append(matches, ipamRecord)
+		}
+	}
+
+	if len(matches) < 1 {
+		return nil, errors.New(fmt.Sprintf("athena.apiClient: Could not find %s '%s' for policy %d!", IPAMReservationResourceType, hostname, policyID))
+	}
+
+	return exactlyOne(IPAMReservationResourceType, hostname, matches)
+}
+
+// End IPAM
+
+// End vRA Deployment
+
+// Start Custom Names
+
+func (apiClient *AthenaAPIClient) CreateCustomName(ctx context.Context, newCustomName *CustomName) (*CustomName, error) {
+	log.Println("athena.apiClient: CreateCustomName")
+
+	var err error
+	if newCustomName.Policy, newCustomName.WorkspaceURL, err = apiClient.resolvePolicyAndWorkspace(ctx, NamingPolicyResourceType, newCustomName.PolicyID, newCustomName.Policy, newCustomName.WorkspaceURL); err != nil {
 		return nil, err
 	}
 
-	ipamRecord := IPAMReservation{}
+	return createManagedObject[CustomName](ctx, apiClient, CustomNameResourceType, newCustomName)
+}
+
+func (apiClient *AthenaAPIClient) GetCustomName(ctx context.Context, id int) (*CustomName, error) {
+	log.Println("athena.apiClient: GetCustomName")
+
+	return getItem[CustomName](ctx, apiClient, CustomNameResourceType, id)
+}
+
+func (apiClient *AthenaAPIClient) DeleteCustomName(ctx context.Context, id int) error {
+	log.Println("athena.apiClient: DeleteCustomName")
+
+	_, err := apiClient.deleteManagedObject(ctx, CustomNameResourceType, id)
+	return err
+}
+
+// End Custom Names
+
+// Start Microsoft AD
+
+func (apiClient *AthenaAPIClient) CreateMicrosoftADComputerAccount(ctx context.Context, newComputerAccount *MicrosoftADComputerAccount) (*MicrosoftADComputerAccount, error) {
+	log.Println("athena.apiClient: CreateMicrosoftADComputerAccount")
+
+	var err error
+	if newComputerAccount.Policy, newComputerAccount.WorkspaceURL, err = apiClient.resolvePolicyAndWorkspace(ctx, MicrosoftADPolicyResourceType, newComputerAccount.PolicyID, newComputerAccount.Policy, newComputerAccount.WorkspaceURL); err != nil {
+		return nil, err
+	}
+
+	return createManagedObject[MicrosoftADComputerAccount](ctx, apiClient, MicrosoftADComputerAccountResourceType, newComputerAccount)
+}
+
+func (apiClient *AthenaAPIClient) GetMicrosoftADComputerAccount(ctx context.Context, id int) (*MicrosoftADComputerAccount, error) {
+	log.Println("athena.apiClient: GetMicrosoftADComputerAccount")
+
+	return getItem[MicrosoftADComputerAccount](ctx, apiClient, MicrosoftADComputerAccountResourceType, id)
+}
+
+// MoveMicrosoftADComputerAccount moves the computer account to the OU for
+// state, e.g. from the build OU to the final OU.
+func (apiClient *AthenaAPIClient) MoveMicrosoftADComputerAccount(ctx context.Context, id int, state string) (*MicrosoftADComputerAccount, error) {
+	log.Println("athena.apiClient: MoveMicrosoftADComputerAccount")
+
+	config := apiClient.config
+
+	req, err := buildPutRequest(ctx, config, MicrosoftADComputerAccountResourceType, &MicrosoftADComputerAccount{State: state}, id)
+	if err != nil {
+		return nil, err
+	}
+
+	computerAccount := MicrosoftADComputerAccount{}
+
+	_, err = apiClient.handleAsyncRequestAndFetchManagdObject(ctx, req, &computerAccount, "PUT")
+	if err != nil {
+		return nil, err
+	}
+	return &computerAccount, nil
+}
+
+func (apiClient *AthenaAPIClient) DeleteMicrosoftADComputerAccount(ctx context.Context, id int) error {
+	log.Println("athena.apiClient: DeleteMicrosoftADComputerAccount")
+
+	_, err := apiClient.deleteManagedObject(ctx, MicrosoftADComputerAccountResourceType, id)
+	return err
+}
+
+func (apiClient *AthenaAPIClient) GetMicrosoftADPolicy(ctx context.Context, id int) (*MicrosoftADPolicy, error) {
+	log.Println("athena.apiClient: GetMicrosoftADPolicy")
+
+	return getItem[MicrosoftADPolicy](ctx, apiClient, MicrosoftADPolicyResourceType, id)
+}
+
+func (apiClient *AthenaAPIClient) GetMicrosoftADPolicyByName(ctx context.Context, name string) (*MicrosoftADPolicy, error) {
+	log.Println("athena.apiClient: GetMicrosoftADPolicyByName")
+
+	return FindByName[MicrosoftADPolicy](ctx, apiClient, MicrosoftADPolicyResourceType, name)
+}
+
+// End Microsoft AD
+
+// Start DNS
+
+func (apiClient *AthenaAPIClient) CreateDNSReservation(ctx context.Context, newDNSReservation *DNSReservation) (*DNSReservation, error) {
+	log.Println("athena.apiClient: CreateDNSReservation")
+
+	var err error
+	if newDNSReservation.Policy, newDNSReservation.WorkspaceURL, err = apiClient.resolvePolicyAndWorkspace(ctx, DNSPolicyResourceType, newDNSReservation.PolicyID, newDNSReservation.Policy, newDNSReservation.WorkspaceURL); err != nil {
+		return nil, err
+	}
+
+	return createManagedObject[DNSReservation](ctx, apiClient, DNSReservationResourceType, newDNSReservation)
+}
+
+func (apiClient *AthenaAPIClient) GetDNSReservation(ctx context.Context, id int) (*DNSReservation, error) {
+	log.Println("athena.apiClient: GetDNSReservation")
+
+	return getItem[DNSReservation](ctx, apiClient, DNSReservationResourceType, id)
+}
+
+func (apiClient *AthenaAPIClient) DeleteDNSReservation(ctx context.Context, id int) error {
+	log.Println("athena.apiClient: DeleteDNSReservation")
+
+	_, err := apiClient.deleteManagedObject(ctx, DNSReservationResourceType, id)
+	return err
+}
+
+func (apiClient *AthenaAPIClient) GetDNSPolicy(ctx context.Context, id int) (*DNSPolicy, error) {
+	log.Println("athena.apiClient: GetDNSPolicy")
+
+	return getItem[DNSPolicy](ctx, apiClient, DNSPolicyResourceType, id)
+}
+
+func (apiClient *AthenaAPIClient) GetDNSPolicyByName(ctx context.Context, name string) (*DNSPolicy, error) {
+	log.Println("athena.apiClient: GetDNSPolicyByName")
+
+	return FindByName[DNSPolicy](ctx, apiClient, DNSPolicyResourceType, name)
+}
+
+// End DNS
+
+// Start Module Endpoints
+
+func (apiClient *AthenaAPIClient) GetModuleEndpoint(ctx context.Context, id int) (*ModuleEndpoint, error) {
+	log.Println("athena.apiClient: GetModuleEndpoint")
+
+	return getItem[ModuleEndpoint](ctx, apiClient, ModuleEndpointResourceType, id)
+}
+
+// GetModuleEndpointByName finds the endpoint with the given name. When
+// endpointType is set, only endpoints of that type are considered.
+func (apiClient *AthenaAPIClient) GetModuleEndpointByName(ctx context.Context, name string, endpointType string) (*ModuleEndpoint, error) {
+	log.Println("athena.apiClient: GetModuleEndpointByName")
+
+	if endpointType == "" {
+		return FindByName[ModuleEndpoint](ctx, apiClient, ModuleEndpointResourceType, name)
+	}
+
+	query := CollectionQuery{Filters: []string{"name.exact:" + name, "type.exact:" + endpointType}}
+
+	endpoints, err := List[ModuleEndpoint](ctx, apiClient, ModuleEndpointResourceType, query)
+	if err != nil {
+		return nil, err
+	}
+
+	// Endpoints of other types may share the name, so only count those of
+	// endpointType as candidates.
+	var matches []ModuleEndpoint
+	for _, endpoint := range endpoints {
+		if endpoint.Name == name && endpoint.Type == endpointType {
+			matches = append(matches, endpoint)
+		}
+	}
+
+	return exactlyOne(ModuleEndpointResourceType, name, matches)
+}
+
+// End Module Endpoints
+
+// Start Module Deployments
+
+func (apiClient *AthenaAPIClient) CreateModuleDeployment(ctx context.Context, newModuleDeployment *ModuleDeployment) (*ModuleDeployment, error) {
+	log.Println("athena.apiClient: CreateModuleDeployment")
+
+	var err error
+	if newModuleDeployment.Policy, newModuleDeployment.WorkspaceURL, err = apiClient.resolvePolicyAndWorkspace(ctx, ModulePolicyResourceType, newModuleDeployment.PolicyID, newModuleDeployment.Policy, newModuleDeployment.WorkspaceURL); err != nil {
+		return nil, err
+	}
+
+	return createManagedObject[ModuleDeployment](ctx, apiClient, ModuleDepoloymentResourceType, newModuleDeployment)
+}
+
+func (apiClient *AthenaAPIClient) GetModuleDeployment(ctx context.Context, id int) (*ModuleDeployment, error) {
+	log.Println("athena.apiClient: GetModuleDeployment")
+
+	return getItem[ModuleDeployment](ctx, apiClient, ModuleDepoloymentResourceType, id)
+}
+
+// DeleteModuleDeployment runs the policy's deprovisioning and removes the
+// managed object.
+func (apiClient *AthenaAPIClient) DeleteModuleDeployment(ctx context.Context, id int) error {
+	log.Println("athena.apiClient: DeleteModuleDeployment")
+
+	_, err := apiClient.deleteManagedObject(ctx, ModuleDepoloymentResourceType, id)
+	return err
+}
+
+// End Module Deployments
+
+// Start Scripting
+
+// CreateScriptingDeployment runs the policy's provisioning script and waits
+// for it to finish. When the script fails, the deployment is returned along
+// with the error so its provisioning details can be reported.
+func (apiClient *AthenaAPIClient) CreateScriptingDeployment(ctx context.Context, newScriptingDeployment *ScriptingDeployment) (*ScriptingDeployment, error) {
+	log.Println("athena.apiClient: CreateScriptingDeployment")
+
+	var err error
+	if newScriptingDeployment.Policy, newScriptingDeployment.WorkspaceURL, err = apiClient.resolvePolicyAndWorkspace(ctx, ScriptingPolicyResourceType, newScriptingDeployment.PolicyID, newScriptingDeployment.Policy, newScriptingDeployment.WorkspaceURL); err != nil {
+		return nil, err
+	}
+
+	req, err := buildPostRequest(ctx, apiClient.config, ScriptingDeploymentResourceType, newScriptingDeployment)
+	if err != nil {
+		return nil, err
+	}
+
+	scriptingDeployment, err := runManagedObjectJob[ScriptingDeployment](ctx, apiClient, req, "POST")
+	if err == nil && scriptingDeployment == nil {
+		return nil, errors.New(fmt.Sprintf("athena.apiClient: Job did not return the created %s", ScriptingDeploymentResourceType))
+	}
+	return scriptingDeployment, err
+}
+
+func (apiClient *AthenaAPIClient) GetScriptingDeployment(ctx context.Context, id int) (*ScriptingDeployment, error) {
+	log.Println("athena.apiClient: GetScriptingDeployment")
+
+	return getItem[ScriptingDeployment](ctx, apiClient, ScriptingDeploymentResourceType, id)
+}
+
+// DeleteScriptingDeployment runs the policy's deprovisioning script and
+// waits for it to finish. The deprovisioning details are returned, also along
+// with the error of a failed job, when the server still exposes the managed
+// object after the job, otherwise nil.
+func (apiClient *AthenaAPIClient) DeleteScriptingDeployment(ctx context.Context, id int) (*ScriptingDetails, error) {
+	log.Println("athena.apiClient: DeleteScriptingDeployment")
 
-	_, err = handleAsyncRequestAndFetchManagdObject(req, config, &ipamRecord, "POST")
+	req, err := buildDeleteRequest(ctx, apiClient.config, ScriptingDeploymentResourceType, id)
 	if err != nil {
 		return nil, err
 	}
-	return &ipamRecord, nil
+
+	scriptingDeployment, err := runManagedObjectJob[ScriptingDeployment](ctx, apiClient, req, "DELETE")
+	if scriptingDeployment == nil {
+		return nil, err
+	}
+	return scriptingDeployment.DeprovisioningDetails, err
 }
 
-//Get IPAM Reservation
+// End Scripting
 
-func (apiClient *AthenaAPIClient) GetIPAMReservation(id int) (*IPAMReservation, error) {
-	log.Println("athena.apiClient: GetIPAMReservation")
+// Start IPAM Policies
+
+func (apiClient *AthenaAPIClient) GetIPAMPolicy(ctx context.Context, id int) (*IPAMPolicy, error) {
+	log.Println("athena.apiClient: GetIPAMPolicy")
 
 	config := apiClient.config
 
-	url := itemURL(config, IPAMReservationResourceType, id)
+	url := itemURL(config, IPAMPolicyResourceType, id)
 
-	ipamRecord := IPAMReservation{}
-	err := doGet(config, url, &ipamRecord)
+	ipamPolicy := IPAMPolicy{}
+	err := apiClient.doGet(ctx, url, &ipamPolicy)
 	if err != nil {
 		return nil, err
 	}
-	return &ipamRecord, err
+	return &ipamPolicy, nil
 }
 
-//Update IPAM Record
+func (apiClient *AthenaAPIClient) GetIPAMPolicyByName(ctx context.Context, name string) (*IPAMPolicy, error) {
+	log.Println("athena.apiClient: GetIPAMPolicyByName")
 
-func (apiClient *AthenaAPIClient) UpdateIPAMReservation(id int, updatedIPAMReservation *IPAMReservation) (*IPAMReservation, error) {
-	log.Println("athena.apiClient: UpdateIPAMReservation")
+	return FindByName[IPAMPolicy](ctx, apiClient, IPAMPolicyResourceType, name)
+}
+
+// ListIPAMPolicies returns every IPAM policy matching filter, following the
+// API's pagination links.
+func (apiClient *AthenaAPIClient) ListIPAMPolicies(ctx context.Context, filters ...string) ([]IPAMPolicy, error) {
+	log.Println("athena.apiClient: ListIPAMPolicies")
 
-	return nil, errors.New("athena.apiClient: Not implemented yet")
+	return List[IPAMPolicy](ctx, apiClient, IPAMPolicyResourceType, CollectionQuery{Filters: filters})
 }
 
-func (apiClient *AthenaAPIClient) DeleteIPAMReservation(id int) error {
-	log.Println("athena.apiClient: DeleteIPAMReservation")
+// End IPAM Policies
+// Start Workspaces
+
+func (apiClient *AthenaAPIClient) CreateWorkspace(ctx context.Context, newWorkspace *Workspace) (*Workspace, error) {
+	log.Println("athena.apiClient: CreateWorkspace")
 
 	config := apiClient.config
 
-	url := itemURL(config, IPAMReservationResourceType, id)
+	req, err := buildPostRequest(ctx, config, WorkspaceResourceType, newWorkspace)
+	if err != nil {
+		return nil, err
+	}
+
+	workspace := Workspace{}
+	if err = apiClient.doRequest(req, &workspace); err != nil {
+		return nil, err
+	}
+	return &workspace, nil
+}
+
+func (apiClient *AthenaAPIClient) GetWorkspace(ctx context.Context, id int) (*Workspace, error) {
+	log.Println("athena.apiClient: GetWorkspace")
 
-	req, err := http.NewRequest("DELETE", url, nil)
+	config := apiClient.config
+
+	url := itemURL(config, WorkspaceResourceType, id)
+
+	workspace := Workspace{}
+	err := apiClient.doGet(ctx, url, &workspace)
 	if err != nil {
-		return errors.WithMessage(err, fmt.Sprintf("athena.apiClient: Failed to create request DELETE %s", url))
+		return nil, err
 	}
+	return &workspace, nil
+}
 
-	setHeaders(req, config)
+func (apiClient *AthenaAPIClient) GetWorkspaceByName(ctx context.Context, name string) (*Workspace, error) {
+	log.Println("athena.apiClient: GetWorkspaceByName")
 
-	_, err = handleAsyncRequest(req, config, "DELETE")
-	return err
+	return FindByName[Workspace](ctx, apiClient, WorkspaceResourceType, name)
 }
 
-// End IPAM
+func (apiClient *AthenaAPIClient) UpdateWorkspace(ctx context.Context, id int, updatedWorkspace *Workspace) (*Workspace, error) {
+	log.Println("athena.apiClient: UpdateWorkspace")
 
-// End vRA Deployment
+	config := apiClient.config
 
-// Start IPAM Policies
+	updateRequest := workspaceUpdateRequest{
+		Name:        updatedWorkspace.Name,
+		Description: updatedWorkspace.Description,
+	}
 
-func (apiClient *AthenaAPIClient) GetIPAMPolicy(id int) (*IPAMPolicy, error) {
-	log.Println("athena.apiClient: GetIPAMPolicy")
-	return nil, errors.New("athena.apiClient: Not implemented yet")
+	req, err := buildPutRequest(ctx, config, WorkspaceResourceType, &updateRequest, id)
+	if err != nil {
+		return nil, err
+	}
+
+	workspace := Workspace{}
+	if err = apiClient.doRequest(req, &workspace); err != nil {
+		return nil, err
+	}
+	return &workspace, nil
 }
 
-func (apiClient *AthenaAPIClient) GetIPAMPolicyByName(name string) (*IPAMPolicy, error) {
-	log.Println("athena.apiClient: GetIPAMPolicyByName")
+func (apiClient *AthenaAPIClient) DeleteWorkspace(ctx context.Context, id int) error {
+	log.Println("athena.apiClient: DeleteWorkspace")
 
 	config := apiClient.config
 
-	ipamPolicies := IPAMPolicyResponse{}
-	entity, err := findEntityByName(config, name, IPAMPolicyResourceType, &ipamPolicies, "IPAMPolicies", "")
+	url := itemURL(config, WorkspaceResourceType, id)
+
+	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
 	if err != nil {
-		return nil, err
+		return errors.WithMessage(err, fmt.Sprintf("athena.apiClient: Failed to create request DELETE %s", url))
 	}
-	ipamPolicy := entity.(IPAMPolicy)
-	return &ipamPolicy, nil
+
+	setHeaders(req, config)
+
+	return apiClient.doRequest(req, nil)
 }
 
-// End IPAM Policies
+// End Workspaces
 // Start Jobs
 
-func GetJobStatus(id int, config *Config) (*JobStatus, error) {
+func (apiClient *AthenaAPIClient) GetJobStatus(ctx context.Context, id int) (*JobStatus, error) {
 	log.Println("athena.apiClient: GetJobStatus")
 
+	config := apiClient.config
+
 	url := itemURL(config, JobStatusResourceType, id)
 	result := JobStatus{}
 
-	err := doGet(config, url, &result)
+	err := apiClient.doGet(ctx, url, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -301,24 +930,40 @@ func GetJobStatus(id int, config *Config) (*JobStatus, error) {
 
 // End Jobs
 
-func handleAsyncRequestAndFetchManagdObject(req *http.Request, config *Config, responseObject interface{}, httpVerb string) (jobStatus *JobStatus, err error) {
+func (apiClient *AthenaAPIClient) handleAsyncRequestAndFetchManagdObject(ctx context.Context, req *http.Request, responseObject interface{}, httpVerb string) (jobStatus *JobStatus, err error) {
+	config := apiClient.config
 
-	if jobStatus, err = handleAsyncRequest(req, config, httpVerb); err != nil {
+	if jobStatus, err = apiClient.handleAsyncRequest(ctx, req, httpVerb); err != nil {
 		return
 	}
 
 	url := urlFromHref(config, jobStatus.Links.ManagedObject.Href)
-	err = doGet(config, url, &responseObject)
+	err = apiClient.doGet(ctx, url, &responseObject)
+	if err != nil {
+		return nil, err
+	}
+
+	return jobStatus, nil
+}
+
+func (apiClient *AthenaAPIClient) handleAsyncRequest(ctx context.Context, req *http.Request, httpVerb string) (*JobStatus, error) {
+	jobStatus, err := apiClient.runJob(ctx, req, httpVerb)
 	if err != nil {
 		return nil, err
 	}
 
+	if err = checkForJobErrors(jobStatus); err != nil {
+		return nil, err
+	}
+
 	return jobStatus, nil
 }
 
-func handleAsyncRequest(req *http.Request, config *Config, httpVerb string) (jobStatus *JobStatus, err error) {
+// runJob sends req, which starts a job, and waits for the job to finish. A
+// failed job is returned without an error; see checkForJobErrors.
+func (apiClient *AthenaAPIClient) runJob(ctx context.Context, req *http.Request, httpVerb string) (jobStatus *JobStatus, err error) {
 
-	client := getHttpClient(config)
+	client := apiClient.httpClient
 
 	res, err := client.Do(req)
 	if err != nil {
@@ -328,8 +973,7 @@ func handleAsyncRequest(req *http.Request, config *Config, httpVerb string) (job
 
 	body, err := readResponse(res)
 	if err != nil {
-		body, _ := ioutil.ReadAll(req.Body)
-		return jobStatus, errors.WithMessage(err, fmt.Sprintf("athena.apiClient: Failed to read response body from %s %s %s", httpVerb, req.URL, body))
+		return jobStatus, errors.WithMessage(err, "athena.apiClient: Request failed")
 	}
 	defer res.Body.Close()
 
@@ -337,34 +981,27 @@ func handleAsyncRequest(req *http.Request, config *Config, httpVerb string) (job
 		return nil, errors.WithMessage(err, fmt.Sprintf("athena.apiClient: Failed to unmarshal response %s", string(body)))
 	}
 
-	jobStatus, err = waitForJob(jobStatus.ID, config)
-	if err != nil {
-		return
-	}
-
-	if err = checkForJobErrors(jobStatus); err != nil {
-		return nil, err
-	}
-
-	return jobStatus, nil
+	return apiClient.waitForJob(ctx, jobStatus.ID)
 }
 
-func doGet(config *Config, url string, v interface{}) (err error) {
-	req, err := http.NewRequest("GET", url, nil)
+func (apiClient *AthenaAPIClient) doGet(ctx context.Context, url string, v interface{}) (err error) {
+	config := apiClient.config
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return errors.WithMessage(err, fmt.Sprintf("athena.apiClient: Failed to create request GET %s", url))
 	}
 
 	setHeaders(req, config)
 
-	client := getHttpClient(config)
+	client := apiClient.httpClient
 	res, err := client.Do(req)
 	if err != nil {
 		return errors.WithMessage(err, fmt.Sprintf("athena.apiClient: Failed to do request GET %s", url))
 	}
 
 	if err = checkForErrors(res); err != nil {
-		return errors.WithMessage(err, fmt.Sprintf("athena.apiClient: Request failed GET %s", url))
+		return errors.WithMessage(err, "athena.apiClient: Request failed")
 	}
 
 	body, err := ioutil.ReadAll(res.Body)
@@ -380,32 +1017,164 @@ func doGet(config *Config, url string, v interface{}) (err error) {
 	return nil
 }
 
-func waitForJob(jobID int, config *Config) (jobStatus *JobStatus, err error) {
-	jobStatusDescription := ""
-	PollingTimeoutMS := 3600000
-	PollingIntervalMS := 5000
-	startTime := time.Now()
-	for jobStatusDescription != JobSuccess && jobStatusDescription != JobFailed {
-		jobStatus, err = GetJobStatus(jobID, config)
+// doRequest sends a synchronous request and unmarshals the response body into v,
+// if v is not nil.
+func (apiClient *AthenaAPIClient) doRequest(req *http.Request, v interface{}) error {
+	res, err := apiClient.httpClient.Do(req)
+	if err != nil {
+		return errors.WithMessage(err, fmt.Sprintf("athena.apiClient: Failed to do request %s %s", req.Method, req.URL))
+	}
+
+	body, err := readResponse(res)
+	if err != nil {
+		return errors.WithMessage(err, "athena.apiClient: Request failed")
+	}
+	defer res.Body.Close()
+
+	if v == nil || len(body) == 0 {
+		return nil
+	}
+
+	if err = json.Unmarshal(body, v); err != nil {
+		return errors.WithMessage(err, fmt.Sprintf("athena.apiClient: Failed to unmarshal response %s", string(body)))
+	}
+
+	return nil
+}
+
+// CollectionQuery narrows a collection listing. Filters are ATHENA filter
+// expressions such as "name.exact:Default"; PageSize defaults to DefaultPageSize.
+type CollectionQuery struct {
+	Filters  []string
+	PageSize int
+}
+
+func (query CollectionQuery) encode() string {
+	values := url.Values{}
+	if len(query.Filters) > 0 {
+		values.Set("filter", strings.Join(query.Filters, ";"))
+	}
+	pageSize := query.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	values.Set("page_size", strconv.Itoa(pageSize))
+	return values.Encode()
+}
+
+// CollectionResponse is the HAL envelope of one page of a collection. ATHENA
+// embeds the items under the collection's resource type.
+type CollectionResponse[T any] struct {
+	Links *struct {
+		Next LinkRef `json:"next,omitempty"`
+	} `json:"_links,omitempty"`
+	Embedded map[string][]T `json:"_embedded"`
+}
+
+// List returns every T in resourceType matching query, following _links.next
+// until the last page.
+func List[T any](ctx context.Context, apiClient *AthenaAPIClient, resourceType string, query CollectionQuery) ([]T, error) {
+	config := apiClient.config
+
+	var items []T
+	pageURL := fmt.Sprintf("%s?%s", collectionURL(config, resourceType), query.encode())
+	for pageURL != "" {
+		page := CollectionResponse[T]{}
+		if err := apiClient.doGet(ctx, pageURL, &page); err != nil {
+			return nil, err
+		}
+		items = append(items, page.Embedded[resourceType]...)
+
+		pageURL = ""
+		if page.Links != nil && page.Links.Next.Href != "" {
+			pageURL = urlFromHref(config, page.Links.Next.Href)
+		}
+	}
+
+	return items, nil
+}
+
+// waitForJob polls the job until it succeeds or fails. Polling stops when ctx is
+// done; if ctx carries no deadline, DefaultJobTimeout is applied.
+func (apiClient *AthenaAPIClient) waitForJob(ctx context.Context, jobID int) (jobStatus *JobStatus, err error) {
+	config := apiClient.config
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultJobTimeout)
+		defer cancel()
+	}
+
+	interval := config.jobPollInterval
+	for {
+		jobStatus, err = apiClient.GetJobStatus(ctx, jobID)
 		if err != nil {
+			if ctx.Err() != nil {
+				return nil, jobWaitError(ctx, jobID)
+			}
 			return nil, err
 		}
 
-		jobStatusDescription = jobStatus.JobState
 		log.Println(jobStatus)
+		if jobStatus.JobState == JobSuccess || jobStatus.JobState == JobFailed {
+			return jobStatus, nil
+		}
 
-		time.Sleep(time.Duration(PollingIntervalMS) * time.Millisecond)
-		if time.Since(startTime) > (time.Duration(PollingTimeoutMS) * time.Millisecond) {
-			return nil, errors.New("Timed out while waiting for job to complete.")
+		timer := time.NewTimer(withJitter(interval))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, jobWaitError(ctx, jobID)
+		case <-timer.C:
 		}
+
+		interval = nextPollInterval(interval, config)
 	}
-	return jobStatus, nil
 }
 
-func findWorkspaceURLOrDefault(config *Config, workspaceURL string) (string, error) {
+func jobWaitError(ctx context.Context, jobID int) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return errors.New(fmt.Sprintf("athena.apiClient: Timed out while waiting for job %d to complete", jobID))
+	}
+	return errors.WithMessage(ctx.Err(), fmt.Sprintf("athena.apiClient: Cancelled while waiting for job %d to complete", jobID))
+}
+
+// nextPollInterval grows the interval by the configured backoff multiplier,
+// capped at the configured maximum interval.
+func nextPollInterval(interval time.Duration, config *Config) time.Duration {
+	next := time.Duration(float64(interval) * config.jobPollBackoff)
+	if next > config.jobPollMaxInterval {
+		next = config.jobPollMaxInterval
+	}
+	if next < config.jobPollInterval {
+		next = config.jobPollInterval
+	}
+	return next
+}
+
+// withJitter randomises the interval by up to 20% in either direction so that
+// parallel resources don't poll the API in lockstep.
+func withJitter(interval time.Duration) time.Duration {
+	spread := int64(interval) / 5
+	if spread <= 0 {
+		return interval
+	}
+	return interval + time.Duration(rand.Int63n(2*spread)-spread)
+}
+
+func (apiClient *AthenaAPIClient) findWorkspaceURLOrDefault(ctx context.Context, workspaceURL string) (string, error) {
+	config := apiClient.config
+
+	if workspaceURL != "" {
+		return workspaceURL, nil
+	}
+
+	apiClient.workspaceMu.Lock()
+	defer apiClient.workspaceMu.Unlock()
+
 	// Default workspace if it was not provided
-	if workspaceURL == "" {
-		workspaceID, err := findDefaultWorkspaceID(config)
+	if apiClient.defaultWorkspaceURL == "" {
+		workspaceID, err := apiClient.findDefaultWorkspaceID(ctx)
 		if err != nil {
 			return "", errors.WithMessage(err, "athena.apiClient: Failed to find default workspace")
 		}
@@ -414,14 +1183,37 @@ func findWorkspaceURLOrDefault(config *Config, workspaceURL string) (string, err
 			return "", errors.WithMessage(err, fmt.Sprintf("athena.apiClient: Failed to convert Workspace ID '%s' to integer", workspaceID))
 		}
 
-		workspaceURL = itemURL(config, WorkspaceResourceType, workspaceIDInt)
+		apiClient.defaultWorkspaceURL = itemURL(config, WorkspaceResourceType, workspaceIDInt)
+	}
+	return apiClient.defaultWorkspaceURL, nil
+}
+
+// SetDefaultWorkspace resolves the workspace, given by name or ID, that is used
+// for objects created without one.
+func (apiClient *AthenaAPIClient) SetDefaultWorkspace(ctx context.Context, nameOrID string) error {
+	log.Println("athena.apiClient: SetDefaultWorkspace")
+
+	var workspace *Workspace
+	var err error
+	if id, convErr := strconv.Atoi(nameOrID); convErr == nil {
+		workspace, err = apiClient.GetWorkspace(ctx, id)
+	} else {
+		workspace, err = apiClient.GetWorkspaceByName(ctx, nameOrID)
+	}
+	if err != nil {
+		return errors.WithMessage(err, fmt.Sprintf("athena.apiClient: Failed to find workspace '%s'", nameOrID))
 	}
-	return workspaceURL, nil
+
+	apiClient.workspaceMu.Lock()
+	defer apiClient.workspaceMu.Unlock()
+
+	apiClient.defaultWorkspaceURL = itemURL(apiClient.config, WorkspaceResourceType, workspace.ID)
+	return nil
 }
 
 // Start Render Template
 
-func (apiClient *AthenaAPIClient) RenderTemplate(template string, templateProperties map[string]interface{}) (*RenderTemplateResponse, error) {
+func (apiClient *AthenaAPIClient) RenderTemplate(ctx context.Context, template string, templateProperties map[string]interface{}) (*RenderTemplateResponse, error) {
 	// this API endpoint is a POST, but only so we can pass in a body to be rendered by the templating engine
 	// it behaves mostly like a GET, and doesn't create an object, just returns the rendered value.
 	log.Println("athena.apiClient: RenderTemplate")
@@ -436,11 +1228,11 @@ func (apiClient *AthenaAPIClient) RenderTemplate(template string, templateProper
 	var err error
 
 	var req *http.Request
-	if req, err = buildPostRequest(config, RenderTemplateType, requestBody); err != nil {
+	if req, err = buildPostRequest(ctx, config, RenderTemplateType, requestBody); err != nil {
 		return nil, err
 	}
 
-	client := getHttpClient(config)
+	client := apiClient.httpClient
 
 	res, err := client.Do(req)
 	if err != nil {
@@ -449,8 +1241,7 @@ func (apiClient *AthenaAPIClient) RenderTemplate(template string, templateProper
 	}
 
 	if err = checkForErrors(res); err != nil {
-		body, _ := ioutil.ReadAll(req.Body)
-		return nil, errors.WithMessage(err, fmt.Sprintf("athena.apiClient: Request failed POST %s %s", req.URL, body))
+		return nil, errors.WithMessage(err, "athena.apiClient: Request failed")
 	}
 
 	body, err := ioutil.ReadAll(res.Body)
@@ -471,74 +1262,138 @@ func (apiClient *AthenaAPIClient) RenderTemplate(template string, templateProper
 
 // End Render Template
 
-func findDefaultWorkspaceID(config *Config) (workspaceID string, err error) {
-	fmt.Println("athena.findDefaultWorkspaceID")
+func (apiClient *AthenaAPIClient) findDefaultWorkspaceID(ctx context.Context) (workspaceID string, err error) {
+	log.Println("athena.findDefaultWorkspaceID")
 
-	filter := "filter=name.exact:Default"
-	url := fmt.Sprintf("%s?%s", collectionURL(config, WorkspaceResourceType), filter)
+	workspace, err := apiClient.GetWorkspaceByName(ctx, "Default")
+	if err != nil {
+		return "", errors.WithMessage(err, "athena.findDefaultWorkspaceID: Failed to find default workspace!")
+	}
 
-	req, clientErr := http.NewRequest("GET", url, nil)
-	if clientErr != nil {
-		err = errors.WithMessage(clientErr, fmt.Sprintf("athena.findDefaultWorkspaceID: Failed to make request GET %s", url))
-		return
+	return strconv.Itoa(workspace.ID), nil
+}
+
+// FindByName returns the single T in resourceType whose name is exactly name,
+// failing with the list of candidates if more than one matches.
+func FindByName[T namedEntity](ctx context.Context, apiClient *AthenaAPIClient, resourceType string, name string, additionalFilters ...string) (*T, error) {
+	query := CollectionQuery{Filters: append([]string{"name.exact:" + name}, additionalFilters...)}
+
+	entities, err := List[T](ctx, apiClient, resourceType, query)
+	if err != nil {
+		return nil, err
 	}
 
-	setHeaders(req, config)
+	// Guard against servers that treat the exact filter as a contains match.
+	var matches []T
+	for _, entity := range entities {
+		if entity.entityName() == name {
+			matches = append(matches, entity)
+		}
+	}
 
-	client := getHttpClient(config)
-	res, clientErr := client.Do(req)
-	if clientErr != nil {
-		err = errors.WithMessage(clientErr, fmt.Sprintf("athena.findDefaultWorkspaceID: Failed to do request GET %s", url))
-		return
+	return exactlyOne(resourceType, name, matches)
+}
+
+// exactlyOne returns the only entry in matches, failing with the list of
+// candidates if there is more than one.
+func exactlyOne[T namedEntity](resourceType string, name string, matches []T) (*T, error) {
+	if len(matches) < 1 {
+		return nil, errors.New(fmt.Sprintf("athena.apiClient: Could not find %s '%s'!", resourceType, name))
 	}
 
-	body, err := readResponse(res)
-	if err != nil {
-		return
+	if len(matches) > 1 {
+		var candidates []string
+		for _, match := range matches {
+			candidates = append(candidates, fmt.Sprintf("%s (id %d)", match.entityName(), match.entityID()))
+		}
+		return nil, errors.New(fmt.Sprintf("athena.apiClient: Found %d %s named '%s', expected exactly one: %s", len(matches), resourceType, name, strings.Join(candidates, ", ")))
 	}
-	defer res.Body.Close()
 
-	var data WorkspacesListResponse
-	json.Unmarshal(body, &data)
+	return &matches[0], nil
+}
 
-	workspaces := data.Embedded.Workspaces
-	if len(workspaces) == 0 {
-		err = errors.WithMessage(clientErr, "athena.findDefaultWorkspaceID: Failed to find default workspace!")
-		return
+// getItem fetches the T with the given id from resourceType.
+func getItem[T any](ctx context.Context, apiClient *AthenaAPIClient, resourceType string, id int) (*T, error) {
+	item := new(T)
+	if err := apiClient.doGet(ctx, itemURL(apiClient.config, resourceType, id), item); err != nil {
+		return nil, err
 	}
-	workspaceID = strconv.Itoa(workspaces[0].ID)
-	return
+	return item, nil
 }
 
-func findEntityByName(config *Config, name string, resourceType string, collectionResponse interface{},
-	embeddedStructFieldName string, additionalFilters string) (interface{}, error) {
+// createManagedObject POSTs newObject to resourceType, waits for the job that
+// provisions it and returns the resulting managed object.
+func createManagedObject[T any](ctx context.Context, apiClient *AthenaAPIClient, resourceType string, newObject interface{}) (*T, error) {
+	req, err := buildPostRequest(ctx, apiClient.config, resourceType, newObject)
+	if err != nil {
+		return nil, err
+	}
 
-	url := fmt.Sprintf("%s?filter=name:%s%s", collectionURL(config, resourceType), name, additionalFilters)
+	managedObject := new(T)
+	if _, err = apiClient.handleAsyncRequestAndFetchManagdObject(ctx, req, managedObject, "POST"); err != nil {
+		return nil, err
+	}
+	return managedObject, nil
+}
 
-	err := doGet(config, url, &collectionResponse)
+// deleteManagedObject deletes the managed object with the given id, which runs
+// its policy's deprovisioning, and returns the finished job.
+func (apiClient *AthenaAPIClient) deleteManagedObject(ctx context.Context, resourceType string, id int) (*JobStatus, error) {
+	req, err := buildDeleteRequest(ctx, apiClient.config, resourceType, id)
 	if err != nil {
 		return nil, err
 	}
 
-	embeddedField := reflect.Indirect(reflect.ValueOf(collectionResponse)).FieldByName("Embedded")
-	embedded := embeddedField.Interface()
+	return apiClient.handleAsyncRequest(ctx, req, "DELETE")
+}
 
-	collectionField := reflect.Indirect(reflect.ValueOf(embedded)).FieldByName(embeddedStructFieldName)
+// runManagedObjectJob sends req and waits for the job it starts, then fetches
+// the managed object the job links to. Unlike createManagedObject, the object
+// is also fetched when the job failed and returned along with the JobError, so
+// callers can report what the job left behind. The object is nil when the job
+// links none or it no longer exists.
+func runManagedObjectJob[T any](ctx context.Context, apiClient *AthenaAPIClient, req *http.Request, httpVerb string) (*T, error) {
+	jobStatus, err := apiClient.runJob(ctx, req, httpVerb)
+	if err != nil {
+		return nil, err
+	}
 
-	if collectionField.Len() < 1 {
-		return nil, errors.New(fmt.Sprintf("athena.apiClient: Could not find %s '%s'!", resourceType, name))
+	var managedObject *T
+	var fetchErr error
+	if jobStatus.Links != nil && jobStatus.Links.ManagedObject.Href != "" {
+		managedObject = new(T)
+		if fetchErr = apiClient.doGet(ctx, urlFromHref(apiClient.config, jobStatus.Links.ManagedObject.Href), managedObject); fetchErr != nil {
+			managedObject = nil
+		}
 	}
 
-	entity := collectionField.Index(0).Interface()
+	// The job error explains a missing object better than the failed fetch.
+	if err = checkForJobErrors(jobStatus); err != nil {
+		return managedObject, err
+	}
 
-	return entity, err
+	if fetchErr != nil && !IsNotFound(fetchErr) {
+		return nil, fetchErr
+	}
+	return managedObject, nil
 }
 
-func getHttpClient(config *Config) *http.Client {
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: !config.verifySSL},
+// resolvePolicyAndWorkspace returns the policy and workspace URLs a managed
+// object is created with: the policy URL is built from policyID when not given,
+// and the workspace falls back to the default workspace.
+func (apiClient *AthenaAPIClient) resolvePolicyAndWorkspace(ctx context.Context, policyResourceType string, policyID int, policyURL string, workspaceURL string) (string, string, error) {
+	if policyURL == "" {
+		if policyID == 0 {
+			return "", "", errors.New(fmt.Sprintf("athena.apiClient: Creating from %s requires a PolicyID or Policy URL", policyResourceType))
+		}
+		policyURL = itemURL(apiClient.config, policyResourceType, policyID)
+	}
+
+	workspaceURL, err := apiClient.findWorkspaceURLOrDefault(ctx, workspaceURL)
+	if err != nil {
+		return "", "", err
 	}
-	return &http.Client{Transport: tr}
+	return policyURL, workspaceURL, nil
 }
 
 func readResponse(res *http.Response) (bytes []byte, err error) {
@@ -548,31 +1403,144 @@ func readResponse(res *http.Response) (bytes []byte, err error) {
 	}
 
 	bytes, err = ioutil.ReadAll(res.Body)
+	if err != nil && res.Request != nil {
+		err = errors.WithMessage(err, fmt.Sprintf("Failed to read response body from %s %s", res.Request.Method, res.Request.URL))
+	}
 	return
 }
 
+// APIError is returned when the Athena API responds with a 4xx or 5xx status.
+type APIError struct {
+	StatusCode   int
+	Method       string
+	URL          string
+	RequestID    string
+	Body         string
+	ErrorDetails *ErrorDetails
+}
+
+func (e *APIError) Error() string {
+	detail := e.Body
+	if messages := e.Messages(); len(messages) > 0 {
+		detail = strings.Join(messages, "; ")
+	}
+	if e.RequestID != "" {
+		return fmt.Sprintf("%s %s returned %d (request %s): %s", e.Method, e.URL, e.StatusCode, e.RequestID, detail)
+	}
+	return fmt.Sprintf("%s %s returned %d: %s", e.Method, e.URL, e.StatusCode, detail)
+}
+
+// Messages returns the error messages parsed from the response body, if any.
+func (e *APIError) Messages() []string {
+	var messages []string
+	if e.ErrorDetails != nil && e.ErrorDetails.Errors != nil {
+		for _, apiErr := range *e.ErrorDetails.Errors {
+			messages = append(messages, apiErr.Message)
+		}
+	}
+	return messages
+}
+
+func hasStatus(err error, statusCode int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == statusCode
+}
+
+// IsNotFound reports whether err was caused by a 404 Not Found response.
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsConflict reports whether err was caused by a 409 Conflict response.
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+// IsUnauthorized reports whether err was caused by a 401 Unauthorized response.
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+// IsForbidden reports whether err was caused by a 403 Forbidden response.
+func IsForbidden(err error) bool {
+	return hasStatus(err, http.StatusForbidden)
+}
+
+// IsBadRequest reports whether err was caused by a 400 Bad Request response,
+// which Athena returns for validation errors.
+func IsBadRequest(err error) bool {
+	return hasStatus(err, http.StatusBadRequest)
+}
+
 func checkForErrors(res *http.Response) error {
-	if res.StatusCode >= 500 {
-		b, err := ioutil.ReadAll(res.Body)
-		if err != nil {
-			return err
+	if res.StatusCode < 400 {
+		return nil
+	}
+
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	apiErr := &APIError{
+		StatusCode: res.StatusCode,
+		RequestID:  res.Header.Get("X-Request-Id"),
+		Body:       string(b),
+	}
+	if res.Request != nil {
+		apiErr.Method = res.Request.Method
+		apiErr.URL = res.Request.URL.String()
+	}
+
+	// Athena reports errors either as a bare errorDetails structure or wrapped
+	// in an errorDetails field, as it does for jobs.
+	errorResponse := struct {
+		Wrapped *ErrorDetails `json:"errorDetails,omitempty"`
+		ErrorDetails
+	}{}
+	if json.Unmarshal(b, &errorResponse) == nil {
+		if errorResponse.Wrapped != nil {
+			apiErr.ErrorDetails = errorResponse.Wrapped
+		} else if errorResponse.Errors != nil {
+			apiErr.ErrorDetails = &ErrorDetails{Code: errorResponse.Code, Errors: errorResponse.Errors}
 		}
-		defer res.Body.Close()
-		return errors.New(string(b))
-	} else if res.StatusCode >= 400 {
-		b, err := ioutil.ReadAll(res.Body)
-		if err != nil {
-			return err
+	}
+
+	return apiErr
+}
+
+// JobError is returned when an Athena job finishes in a state other than JobSuccess.
+type JobError struct {
+	JobStatus *JobStatus
+}
+
+func (e *JobError) Error() string {
+	return fmt.Sprintf("Job %s (%d) failed with message %v", e.JobStatus.JobType, e.JobStatus.ID, e.Messages())
+}
+
+// Summary returns a one-line description of the failed job.
+func (e *JobError) Summary() string {
+	return fmt.Sprintf("Job %s (%d) finished in state %s", e.JobStatus.JobType, e.JobStatus.ID, e.JobStatus.JobState)
+}
+
+// Messages returns the error messages reported by the job, if any.
+func (e *JobError) Messages() []string {
+	var messages []string
+	if e.JobStatus.ErrorDetails != nil && e.JobStatus.ErrorDetails.Errors != nil {
+		for _, jobErr := range *e.JobStatus.ErrorDetails.Errors {
+			messages = append(messages, jobErr.Message)
 		}
-		defer res.Body.Close()
-		return errors.New(string(b))
 	}
-	return nil
+	if len(messages) == 0 && e.JobStatus.JobStateDescription != "" {
+		messages = append(messages, e.JobStatus.JobStateDescription)
+	}
+	return messages
 }
 
 func checkForJobErrors(jobStatus *JobStatus) error {
 	if jobStatus.JobState != JobSuccess {
-		return errors.New(fmt.Sprintf("Job %s (%d) failed with message %v", jobStatus.JobType, jobStatus.ID, *jobStatus.ErrorDetails.Errors))
+		return &JobError{JobStatus: jobStatus}
 	}
 	return nil
 }
@@ -588,19 +1556,37 @@ func setStandardHeaders(req *http.Request) {
 
 func setHeaders(req *http.Request, config *Config) {
 	setStandardHeaders(req)
-	req.Header.Add("Host", fmt.Sprintf("%s:%s", config.address, config.port))
 	req.Header.Add("SOURCE", "Terraform")
-	req.SetBasicAuth(config.user, config.password)
 }
 
 func collectionURL(config *Config, resourceType string) string {
-	baseURL := fmt.Sprintf("%s://%s:%s", config.scheme, config.address, config.port)
 	endpoint := path.Join(ApiVersion, ApiNamespace, resourceType)
-	return fmt.Sprintf("%s/%s/", baseURL, endpoint)
+	return config.endpoint.ResolveReference(&url.URL{Path: endpoint + "/"}).String()
 }
 
+// urlFromHref resolves a HAL href against the configured endpoint. Host-relative
+// hrefs that don't already include the endpoint's path prefix have it added, so
+// links keep working when ATHENA is served behind a reverse proxy.
 func urlFromHref(config *Config, href string) string {
-	return fmt.Sprintf("%s://%s:%s%s", config.scheme, config.address, config.port, href)
+	ref, err := url.Parse(href)
+	if err != nil || ref.IsAbs() {
+		return href
+	}
+
+	prefix := strings.TrimSuffix(config.endpoint.Path, "/")
+	if strings.HasPrefix(ref.Path, "/") && !strings.HasPrefix(ref.Path, prefix+"/") {
+		ref.Path = prefix + ref.Path
+	}
+
+	return config.endpoint.ResolveReference(ref).String()
+}
+
+// idFromHref returns the trailing numeric ID of an item href such as
+// /api/v3/onefuse/ipamPolicies/2/, or 0 if there is none.
+func idFromHref(href string) int {
+	hrefSplit := strings.Split(strings.TrimSuffix(href, "/"), "/")
+	id, _ := strconv.Atoi(hrefSplit[len(hrefSplit)-1])
+	return id
 }
 
 func itemURL(config *Config, resourceType string, id int) string {
@@ -0,0 +1,227 @@
+// Copyright 2020 CloudBolt Software
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package athena
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// newTestAPIClient returns a client for server that sends requests without
+// authentication or retries.
+func newTestAPIClient(t *testing.T, server *httptest.Server) *AthenaAPIClient {
+	endpoint, err := parseEndpoint(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &AthenaAPIClient{
+		config:     &Config{endpoint: endpoint},
+		httpClient: server.Client(),
+	}
+}
+
+func TestFindByNameRejectsAmbiguousMatches(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if filter := r.URL.Query().Get("filter"); filter != "name.exact:prod" {
+			t.Errorf("unexpected filter %q", filter)
+		}
+		fmt.Fprint(w, `{"_embedded":{"workspaces":[
+			{"id":3,"name":"prod"},
+			{"id":4,"name":"prod-eu"},
+			{"id":7,"name":"prod"}
+		]}}`)
+	}))
+	defer server.Close()
+
+	_, err := FindByName[Workspace](context.Background(), newTestAPIClient(t, server), WorkspaceResourceType, "prod")
+	if err == nil {
+		t.Fatal("expected an error for two workspaces named prod")
+	}
+	if !strings.Contains(err.Error(), "prod (id 3), prod (id 7)") {
+		t.Errorf("expected the candidates in the error, got: %s", err)
+	}
+}
+
+func TestFindByNameFollowsPagesAndIgnoresPartialMatches(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `{"_embedded":{"workspaces":[{"id":9,"name":"prod"}]}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"_links":{"next":{"href":"%s?page=2"}},"_embedded":{"workspaces":[{"id":4,"name":"prod-eu"}]}}`, r.URL.Path)
+	}))
+	defer server.Close()
+
+	workspace, err := FindByName[Workspace](context.Background(), newTestAPIClient(t, server), WorkspaceResourceType, "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if workspace.ID != 9 {
+		t.Errorf("expected workspace 9, got %d", workspace.ID)
+	}
+}
+
+func TestFindByNameNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"_embedded":{"workspaces":[]}}`)
+	}))
+	defer server.Close()
+
+	_, err := FindByName[Workspace](context.Background(), newTestAPIClient(t, server), WorkspaceResourceType, "prod")
+	if err == nil || !strings.Contains(err.Error(), "Could not find") {
+		t.Errorf("expected a not found error, got: %v", err)
+	}
+}
+
+func TestGetModuleEndpointByNameFiltersByType(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if filter := r.URL.Query().Get("filter"); filter != "name.exact:corp;type.exact:ad" {
+			t.Errorf("unexpected filter %q", filter)
+		}
+		// A server that ignores the type filter still returns both endpoints.
+		fmt.Fprint(w, `{"_embedded":{"endpoints":[
+			{"id":3,"name":"corp","type":"ad"},
+			{"id":4,"name":"corp","type":"bluecat_ad"}
+		]}}`)
+	}))
+	defer server.Close()
+
+	endpoint, err := newTestAPIClient(t, server).GetModuleEndpointByName(context.Background(), "corp", "ad")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if endpoint.ID != 3 {
+		t.Errorf("expected endpoint 3, got %d", endpoint.ID)
+	}
+}
+
+func TestGetIPAMReservationByHostnameRejectsAmbiguousMatches(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"_embedded":{"ipamReservations":[
+			{"id":5,"hostname":"web01","_links":{"policy":{"href":"/api/v3/onefuse/ipamPolicies/2/"}}},
+			{"id":6,"hostname":"web01","_links":{"policy":{"href":"/api/v3/onefuse/ipamPolicies/3/"}}},
+			{"id":7,"hostname":"web01"},
+			{"id":8,"hostname":"web01","_links":{"policy":{"href":"/api/v3/onefuse/ipamPolicies/2/"}}}
+		]}}`)
+	}))
+	defer server.Close()
+
+	_, err := newTestAPIClient(t, server).GetIPAMReservationByHostname(context.Background(), "web01", 2)
+	if err == nil || !strings.Contains(err.Error(), "Found 2 ipamReservations named 'web01', expected exactly one: web01 (id 5), web01 (id 8)") {
+		t.Errorf("expected the candidates in the error, got: %v", err)
+	}
+}
+
+func TestUpdateWorkspaceSendsEmptyDescription(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Method != "PUT" || string(body) != `{"name":"prod","description":""}` {
+			t.Errorf("unexpected request %s %s", r.Method, body)
+		}
+		fmt.Fprint(w, `{"id":3,"name":"prod"}`)
+	}))
+	defer server.Close()
+
+	_, err := newTestAPIClient(t, server).UpdateWorkspace(context.Background(), 3, &Workspace{Name: "prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newTestJobClient returns a client for server that polls jobs every
+// millisecond.
+func newTestJobClient(t *testing.T, server *httptest.Server) *AthenaAPIClient {
+	apiClient := newTestAPIClient(t, server)
+	apiClient.config.jobPollInterval = time.Millisecond
+	apiClient.config.jobPollBackoff = 2
+	apiClient.config.jobPollMaxInterval = 4 * time.Millisecond
+	return apiClient
+}
+
+func TestWaitForJobCancelledMidPoll(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		if polls == 2 {
+			cancel()
+		}
+		fmt.Fprint(w, `{"id":12,"jobState":"Pending"}`)
+	}))
+	defer server.Close()
+
+	_, err := newTestJobClient(t, server).waitForJob(ctx, 12)
+	if err == nil || !strings.Contains(err.Error(), "Cancelled while waiting for job 12 to complete") {
+		t.Errorf("expected a cancellation error, got: %v", err)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the error to wrap context.Canceled, got: %v", err)
+	}
+}
+
+func TestWaitForJobTimesOut(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":12,"jobState":"Pending"}`)
+	}))
+	defer server.Close()
+
+	_, err := newTestJobClient(t, server).waitForJob(ctx, 12)
+	if err == nil || err.Error() != "athena.apiClient: Timed out while waiting for job 12 to complete" {
+		t.Errorf("expected a timeout error, got: %v", err)
+	}
+}
+
+func TestNextPollIntervalGrowsToMax(t *testing.T) {
+	config := &Config{jobPollInterval: time.Second, jobPollBackoff: 2, jobPollMaxInterval: 5 * time.Second}
+
+	expected := []time.Duration{2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	interval := config.jobPollInterval
+	for i, want := range expected {
+		interval = nextPollInterval(interval, config)
+		if interval != want {
+			t.Errorf("poll %d: expected %s, got %s", i+2, want, interval)
+		}
+	}
+}
+
+func TestURLFromHref(t *testing.T) {
+	endpoint, err := parseEndpoint("https://gw.example.com/athena/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &Config{endpoint: endpoint}
+
+	cases := []struct {
+		href     string
+		expected string
+	}{
+		{"/api/v3/onefuse/jobStatus/12/", "https://gw.example.com/athena/api/v3/onefuse/jobStatus/12/"},
+		{"/athena/api/v3/onefuse/jobStatus/12/", "https://gw.example.com/athena/api/v3/onefuse/jobStatus/12/"},
+		{"https://athena.example.com/api/v3/onefuse/jobStatus/12/", "https://athena.example.com/api/v3/onefuse/jobStatus/12/"},
+		{"/api/v3/onefuse/workspaces/?page=2&filter=name.exact:prod", "https://gw.example.com/athena/api/v3/onefuse/workspaces/?page=2&filter=name.exact:prod"},
+	}
+
+	for _, c := range cases {
+		if got := urlFromHref(config, c.href); got != c.expected {
+			t.Errorf("urlFromHref(%q) = %q; expected %q", c.href, got, c.expected)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2020 CloudBolt Software
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package athena
+
+import (
+	"context"
+	"log"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceADPolicy() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceADPolicyRead,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"name", "id"},
+			},
+			"id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"name", "id"},
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"url": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"workspace_url": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"computer_name_letter_case": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"ou": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"create_ou": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"remove_ou": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"security_groups": {
+				Type: schema.TypeList,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceADPolicyRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Println("athena.dataSourceADPolicyRead")
+
+	apiClient := meta.(*AthenaAPIClient)
+
+	var adPolicy *MicrosoftADPolicy
+	var err error
+	if id, ok := d.GetOk("id"); ok {
+		intID, convErr := strconv.Atoi(id.(string))
+		if convErr != nil {
+			return diag.Errorf("AD Policy id must be numeric, got: %s", id)
+		}
+		adPolicy, err = apiClient.GetMicrosoftADPolicy(ctx, intID)
+	} else {
+		adPolicy, err = apiClient.GetMicrosoftADPolicyByName(ctx, d.Get("name").(string))
+	}
+
+	if err != nil {
+		return diagFromError("Error loading AD Policy", err)
+	}
+
+	d.SetId(strconv.Itoa(adPolicy.ID))
+	d.Set("name", adPolicy.Name)
+	d.Set("description", adPolicy.Description)
+	if adPolicy.Links != nil {
+		d.Set("url", adPolicy.Links.Self.Href)
+		d.Set("workspace_url", adPolicy.Links.Workspace.Href)
+	}
+	d.Set("computer_name_letter_case", adPolicy.ComputerNameLetterCase)
+	d.Set("ou", adPolicy.OU)
+	d.Set("create_ou", adPolicy.CreateOU)
+	d.Set("remove_ou", adPolicy.RemoveOU)
+	d.Set("security_groups", adPolicy.SecurityGroups)
+
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2020 CloudBolt Software
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package athena
+
+import (
+	"context"
+	"log"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceDNSPolicy() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceDNSPolicyRead,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"name", "id"},
+			},
+			"id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"name", "id"},
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"url": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"workspace_url": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceDNSPolicyRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Println("athena.dataSourceDNSPolicyRead")
+
+	apiClient := meta.(*AthenaAPIClient)
+
+	var dnsPolicy *DNSPolicy
+	var err error
+	if id, ok := d.GetOk("id"); ok {
+		intID, convErr := strconv.Atoi(id.(string))
+		if convErr != nil {
+			return diag.Errorf("DNS Policy id must be numeric, got: %s", id)
+		}
+		dnsPolicy, err = apiClient.GetDNSPolicy(ctx, intID)
+	} else {
+		dnsPolicy, err = apiClient.GetDNSPolicyByName(ctx, d.Get("name").(string))
+	}
+
+	if err != nil {
+		return diagFromError("Error loading DNS Policy", err)
+	}
+
+	d.SetId(strconv.Itoa(dnsPolicy.ID))
+	d.Set("name", dnsPolicy.Name)
+	d.Set("description", dnsPolicy.Description)
+	if dnsPolicy.Links != nil {
+		d.Set("url", dnsPolicy.Links.Self.Href)
+		d.Set("workspace_url", dnsPolicy.Links.Workspace.Href)
+	}
+
+	return nil
+}
@@ -7,48 +7,154 @@
 package athena
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"regexp"
 	"strconv"
 
-	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
-func dataSourceIPAMPolicy() *schema.Resource {
+const MatchModeExact = "exact"
+const MatchModeContains = "contains"
+
+func dataSourceIPAMPolicies() *schema.Resource {
 	return &schema.Resource{
-		Read: dataSourceIPAMPolicyRead,
+		ReadContext: dataSourceIPAMPoliciesRead,
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
-				Required: true,
+				Optional: true,
+			},
+			"match_mode": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      MatchModeExact,
+				ValidateFunc: validation.StringInSlice([]string{MatchModeExact, MatchModeContains}, false),
 			},
-			"id": {
+			"name_regex": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+			"workspace_url": {
 				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"ids": {
+				Type: schema.TypeList,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
 				Computed: true,
 			},
-			"description": {
-				Type:     schema.TypeString,
+			"names": {
+				Type: schema.TypeList,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Computed: true,
+			},
+			"policies": {
+				Type:     schema.TypeList,
 				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"description": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"url": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"workspace_url": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
 			},
 		},
 	}
 }
 
-func dataSourceIPAMPolicyRead(d *schema.ResourceData, meta interface{}) error {
-	log.Println("athena.dataSourceIPAMPolicyRead")
+func dataSourceIPAMPoliciesRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Println("athena.dataSourceIPAMPoliciesRead")
 
-	config := meta.(Config)
-	apiClient := config.NewAthenaApiClient()
+	apiClient := meta.(*AthenaAPIClient)
 
-	ipamPolicy, err := apiClient.GetIPAMPolicyByName(d.Get("name").(string))
+	name := d.Get("name").(string)
+	matchMode := d.Get("match_mode").(string)
+	nameRegex := d.Get("name_regex").(string)
+	workspaceURL := d.Get("workspace_url").(string)
 
+	var filters []string
+	if name != "" {
+		if matchMode == MatchModeExact {
+			filters = append(filters, "name.exact:"+name)
+		} else {
+			filters = append(filters, "name:"+name)
+		}
+	}
+
+	var nameMatcher *regexp.Regexp
+	if nameRegex != "" {
+		nameMatcher = regexp.MustCompile(nameRegex)
+	}
+
+	ipamPolicies, err := apiClient.ListIPAMPolicies(ctx, filters...)
 	if err != nil {
-		return fmt.Errorf("Error loading IPAM Policy: %s", err)
+		return diagFromError("Error listing IPAM Policies", err)
+	}
+
+	ids := []string{}
+	names := []string{}
+	policies := []map[string]interface{}{}
+	for _, ipamPolicy := range ipamPolicies {
+		if name != "" && matchMode == MatchModeExact && ipamPolicy.Name != name {
+			continue
+		}
+		if nameMatcher != nil && !nameMatcher.MatchString(ipamPolicy.Name) {
+			continue
+		}
+
+		policyURL, policyWorkspaceURL := "", ""
+		if ipamPolicy.Links != nil {
+			policyURL = ipamPolicy.Links.Self.Href
+			policyWorkspaceURL = ipamPolicy.Links.Workspace.Href
+		}
+		if workspaceURL != "" && idFromHref(policyWorkspaceURL) != idFromHref(workspaceURL) {
+			continue
+		}
+
+		id := strconv.Itoa(ipamPolicy.ID)
+		ids = append(ids, id)
+		names = append(names, ipamPolicy.Name)
+		policies = append(policies, map[string]interface{}{
+			"id":            id,
+			"name":          ipamPolicy.Name,
+			"description":   ipamPolicy.Description,
+			"url":           policyURL,
+			"workspace_url": policyWorkspaceURL,
+		})
 	}
 
-	d.SetId(strconv.Itoa(ipamPolicy.ID))
-	d.Set("name", ipamPolicy.Name)
-	d.Set("description", ipamPolicy.Description)
+	d.SetId(fmt.Sprintf("%s|%s|%s|%s", name, matchMode, nameRegex, workspaceURL))
+	d.Set("ids", ids)
+	d.Set("names", names)
+	d.Set("policies", policies)
 
 	return nil
 }
@@ -0,0 +1,131 @@
+// Copyright 2020 CloudBolt Software
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package athena
+
+import (
+	"context"
+	"log"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceIPAMPolicy() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceIPAMPolicyRead,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"name", "id"},
+			},
+			"id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"name", "id"},
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"url": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"workspace_url": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"hostname_template": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"networks": {
+				Type: schema.TypeList,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Computed: true,
+			},
+			"gateway": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"primary_dns": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"secondary_dns": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"dns_suffix": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"dns_search_suffixes": {
+				Type: schema.TypeList,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Computed: true,
+			},
+			"nic_label": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"update_conflict_name_with_dns": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceIPAMPolicyRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Println("athena.dataSourceIPAMPolicyRead")
+
+	apiClient := meta.(*AthenaAPIClient)
+
+	var ipamPolicy *IPAMPolicy
+	var err error
+	if id, ok := d.GetOk("id"); ok {
+		intID, convErr := strconv.Atoi(id.(string))
+		if convErr != nil {
+			return diag.Errorf("IPAM Policy id must be numeric, got: %s", id)
+		}
+		ipamPolicy, err = apiClient.GetIPAMPolicy(ctx, intID)
+	} else {
+		ipamPolicy, err = apiClient.GetIPAMPolicyByName(ctx, d.Get("name").(string))
+	}
+
+	if err != nil {
+		return diagFromError("Error loading IPAM Policy", err)
+	}
+
+	d.SetId(strconv.Itoa(ipamPolicy.ID))
+	d.Set("name", ipamPolicy.Name)
+	d.Set("description", ipamPolicy.Description)
+	if ipamPolicy.Links != nil {
+		d.Set("url", ipamPolicy.Links.Self.Href)
+		d.Set("workspace_url", ipamPolicy.Links.Workspace.Href)
+	}
+	d.Set("hostname_template", ipamPolicy.HostnameOverride)
+	d.Set("networks", ipamPolicy.Networks)
+	d.Set("gateway", ipamPolicy.Gateway)
+	d.Set("primary_dns", ipamPolicy.PrimaryDNS)
+	d.Set("secondary_dns", ipamPolicy.SecondaryDNS)
+	d.Set("dns_suffix", ipamPolicy.DNSSuffix)
+	d.Set("dns_search_suffixes", ipamPolicy.DNSSearchSuffixes)
+	d.Set("nic_label", ipamPolicy.NicLabel)
+	d.Set("update_conflict_name_with_dns", ipamPolicy.UpdateConflictNameWithDNS)
+
+	return nil
+}
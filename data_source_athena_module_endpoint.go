@@ -0,0 +1,92 @@
+// Copyright 2020 CloudBolt Software
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package athena
+
+import (
+	"context"
+	"log"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceModuleEndpoint() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceModuleEndpointRead,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"type": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"url": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"workspace_url": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"host": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"port": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"ssl": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"credential_url": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"credential_id": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceModuleEndpointRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Println("athena.dataSourceModuleEndpointRead")
+
+	apiClient := meta.(*AthenaAPIClient)
+
+	endpoint, err := apiClient.GetModuleEndpointByName(ctx, d.Get("name").(string), d.Get("type").(string))
+	if err != nil {
+		return diagFromError("Error loading Module Endpoint", err)
+	}
+
+	d.SetId(strconv.Itoa(endpoint.ID))
+	d.Set("name", endpoint.Name)
+	d.Set("type", endpoint.Type)
+	d.Set("description", endpoint.Description)
+	d.Set("host", endpoint.Host)
+	d.Set("port", endpoint.Port)
+	d.Set("ssl", endpoint.SSL)
+	if endpoint.Links != nil {
+		d.Set("url", endpoint.Links.Self.Href)
+		d.Set("workspace_url", endpoint.Links.Workspace.Href)
+		d.Set("credential_url", endpoint.Links.Credential.Href)
+		d.Set("credential_id", idFromHref(endpoint.Links.Credential.Href))
+	}
+
+	return nil
+}
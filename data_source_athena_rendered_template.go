@@ -0,0 +1,59 @@
+// Copyright 2020 CloudBolt Software
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package athena
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceRenderedTemplate() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceRenderedTemplateRead,
+		Schema: map[string]*schema.Schema{
+			"template": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"template_properties": {
+				Type:     schema.TypeMap,
+				Optional: true,
+			},
+			"value": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceRenderedTemplateRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Println("athena.dataSourceRenderedTemplateRead")
+
+	apiClient := meta.(*AthenaAPIClient)
+
+	template := d.Get("template").(string)
+	templateProperties := d.Get("template_properties").(map[string]interface{})
+
+	renderedTemplate, err := apiClient.RenderTemplate(ctx, template, templateProperties)
+	if err != nil {
+		return diagFromError("Error rendering template", err)
+	}
+
+	// The ID only needs to be stable for a given template and set of properties.
+	request, _ := json.Marshal(RenderTemplateRequest{Template: template, TemplateProperties: templateProperties})
+	d.SetId(fmt.Sprintf("%x", sha256.Sum256(request)))
+	d.Set("value", renderedTemplate.Value)
+
+	return nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2020 CloudBolt Software
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package athena
+
+import (
+	"context"
+	"log"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceWorkspace() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceWorkspaceRead,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"name", "id"},
+			},
+			"id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"name", "id"},
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"url": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceWorkspaceRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	log.Println("athena.dataSourceWorkspaceRead")
+
+	apiClient := meta.(*AthenaAPIClient)
+
+	var workspace *Workspace
+	var err error
+	if id, ok := d.GetOk("id"); ok {
+		intID, convErr := strconv.Atoi(id.(string))
+		if convErr != nil {
+			return diag.Errorf("Workspace id must be numeric, got: %s", id)
+		}
+		workspace, err = apiClient.GetWorkspace(ctx, intID)
+	} else {
+		workspace, err = apiClient.GetWorkspaceByName(ctx, d.Get("name").(string))
+	}
+
+	if err != nil {
+		return diagFromError("Error loading Workspace", err)
+	}
+
+	d.SetId(strconv.Itoa(workspace.ID))
+	d.Set("name", workspace.Name)
+	d.Set("description", workspace.Description)
+	if workspace.Links != nil {
+		d.Set("url", workspace.Links.Self.Href)
+	}
+
+	return nil
+}
@@ -1,87 +1,479 @@
 package athena
 
 import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/pkg/errors"
 )
 
 func Provider() *schema.Provider {
 	return &schema.Provider{
 		Schema: map[string]*schema.Schema{
+			"endpoint": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ATHENA_ENDPOINT", ""),
+				Description: "ATHENA base URL, including any path prefix, e.g. https://gw.example.com/athena/",
+			},
 			"scheme": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				DefaultFunc: schema.EnvDefaultFunc("ATHENA_SCHEME", "https"),
-				Description: "ATHENA REST endpoint service http(s) scheme",
+				DefaultFunc: schema.EnvDefaultFunc("ATHENA_SCHEME", ""),
+				Description: "ATHENA REST endpoint service http(s) scheme, https by default. Prefer endpoint",
 			},
 			"address": {
 				Type:        schema.TypeString,
-				Required:    true,
-				DefaultFunc: schema.EnvDefaultFunc("ATHENA_ADDRESS", nil),
-				Description: "ATHENA REST endpoint service host address",
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ATHENA_ADDRESS", ""),
+				Description: "ATHENA REST endpoint service host address. Prefer endpoint",
 			},
 			"port": {
 				Type:        schema.TypeString,
-				Required:    true,
-				DefaultFunc: schema.EnvDefaultFunc("ATHENA_PORT", nil),
-				Description: "ATHENA REST endpoint service port number",
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ATHENA_PORT", ""),
+				Description: "ATHENA REST endpoint service port number. Prefer endpoint",
 			},
 			"user": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("ATHENA_USER", nil),
 				Description: "ATHENA REST endpoint user name",
 			},
 			"password": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
 				Sensitive:   true,
 				DefaultFunc: schema.EnvDefaultFunc("ATHENA_PASSWORD", nil),
 				Description: "ATHENA REST endpoint password",
 			},
+			"token": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("ATHENA_TOKEN", ""),
+				Description: "ATHENA API token. When set, it is used instead of user and password",
+			},
+			"session_login": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ATHENA_SESSION_LOGIN", false),
+				Description: "Exchange user and password for a session token once instead of sending basic auth on every request",
+			},
 			"verify_ssl": {
 				Type:        schema.TypeBool,
 				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("ATHENA_VERIFY_SSL", true),
 				Description: "Verify SSL certificates for ATHENA endpoints",
 			},
+			"workspace": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ATHENA_WORKSPACE", ""),
+				Description: "Name or ID of the workspace used by resources that don't set one. Defaults to the workspace named Default",
+			},
+			"ca_cert": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ATHENA_CA_CERT", ""),
+				Description: "PEM encoded CA bundle, or the path to one, used to verify the ATHENA server certificate",
+			},
+			"client_cert": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ATHENA_CLIENT_CERT", ""),
+				Description: "PEM encoded client certificate, or the path to one, for mutual TLS",
+			},
+			"client_key": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("ATHENA_CLIENT_KEY", ""),
+				Description: "PEM encoded client private key, or the path to one, for mutual TLS",
+			},
+			"tls_server_name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ATHENA_TLS_SERVER_NAME", ""),
+				Description: "Server name used to verify the ATHENA certificate, when it differs from address",
+			},
+			"tls_min_version": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("ATHENA_TLS_MIN_VERSION", "1.2"),
+				ValidateFunc: validation.StringInSlice([]string{"1.0", "1.1", "1.2", "1.3"}, false),
+				Description:  "Minimum TLS version accepted from ATHENA",
+			},
+			"job_poll_interval": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ATHENA_JOB_POLL_INTERVAL", 5),
+				Description: "Initial interval in seconds between ATHENA job status checks",
+			},
+			"job_poll_max_interval": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ATHENA_JOB_POLL_MAX_INTERVAL", 60),
+				Description: "Maximum interval in seconds between ATHENA job status checks",
+			},
+			"job_poll_backoff": {
+				Type:        schema.TypeFloat,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ATHENA_JOB_POLL_BACKOFF", 1.5),
+				Description: "Multiplier applied to the job poll interval after each check",
+			},
+			"request_timeout": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ATHENA_REQUEST_TIMEOUT", 60),
+				Description: "Time in seconds to wait for ATHENA to respond to a single request",
+			},
+			"proxy_url": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ATHENA_PROXY_URL", ""),
+				Description: "URL of the HTTP proxy used to reach ATHENA. Defaults to the HTTPS_PROXY environment variable",
+			},
+			"no_proxy": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Hosts, domain suffixes or CIDRs that bypass proxy_url",
+			},
+			"retry_max_attempts": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ATHENA_RETRY_MAX_ATTEMPTS", 4),
+				Description: "Maximum number of attempts for ATHENA requests that fail with a transient error",
+			},
+			"retry_wait_min": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ATHENA_RETRY_WAIT_MIN", 1),
+				Description: "Initial wait in seconds before retrying a failed ATHENA request",
+			},
+			"retry_wait_max": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ATHENA_RETRY_WAIT_MAX", 30),
+				Description: "Maximum wait in seconds before retrying a failed ATHENA request",
+			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"athena_ipam_record": resourceIPAMReservation(),
+			"athena_ad_computer_account":  resourceADComputerAccount(),
+			"athena_custom_name":          resourceCustomName(),
+			"athena_dns_record":           resourceDNSReservation(),
+			"athena_ipam_record":          resourceIPAMReservation(),
+			"athena_module_deployment":    resourceModuleDeployment(),
+			"athena_scripting_deployment": resourceScriptingDeployment(),
+			"athena_workspace":            resourceWorkspace(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"athena_ipam_policy": dataSourceIPAMPolicy(),
+			"athena_ad_policy":         dataSourceADPolicy(),
+			"athena_dns_policy":        dataSourceDNSPolicy(),
+			"athena_ipam_policy":       dataSourceIPAMPolicy(),
+			"athena_module_endpoint":   dataSourceModuleEndpoint(),
+			"athena_ipam_policies":     dataSourceIPAMPolicies(),
+			"athena_rendered_template": dataSourceRenderedTemplate(),
+			"athena_workspace":         dataSourceWorkspace(),
 		},
-		ConfigureFunc: configureProvider,
+		ConfigureContextFunc: configureProvider,
 	}
 }
 
 type Config struct {
-	scheme    string
-	address   string
-	port      string
-	user      string
-	password  string
-	verifySSL bool
+	endpoint           *url.URL
+	user               string
+	password           string
+	token              string
+	sessionLogin       bool
+	verifySSL          bool
+	caCert             string
+	clientCert         string
+	clientKey          string
+	tlsServerName      string
+	tlsMinVersion      uint16
+	jobPollInterval    time.Duration
+	jobPollMaxInterval time.Duration
+	jobPollBackoff     float64
+	retryMaxAttempts   int
+	retryWaitMin       time.Duration
+	retryWaitMax       time.Duration
+	requestTimeout     time.Duration
+	proxyURL           *url.URL
+	noProxy            []string
 }
 
-func configureProvider(d *schema.ResourceData) (interface{}, error) {
-	return NewConfig(
+func configureProvider(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	config := NewConfig(
 		d.Get("scheme").(string),
 		d.Get("address").(string),
 		d.Get("port").(string),
 		d.Get("user").(string),
 		d.Get("password").(string),
 		d.Get("verify_ssl").(bool),
-	), nil
+	)
+
+	if v := d.Get("endpoint").(string); v != "" {
+		// ConflictsWith can't be used here: the SDK fills in defaults for
+		// scheme, address and port before checking it, so only reject values
+		// that were actually written in the configuration.
+		if rawConfig := d.GetRawConfig(); !rawConfig.IsNull() {
+			for _, k := range []string{"scheme", "address", "port"} {
+				if !rawConfig.GetAttr(k).IsNull() {
+					return nil, diag.Errorf("endpoint conflicts with %s; set either endpoint, or scheme, address and port", k)
+				}
+			}
+		}
+
+		endpoint, err := parseEndpoint(v)
+		if err != nil {
+			return nil, diag.Errorf("Invalid endpoint %q: %s", v, err)
+		}
+		config.endpoint = endpoint
+	} else if d.Get("address").(string) == "" {
+		return nil, diag.Errorf("Either endpoint, or address and port, must be set to connect to ATHENA")
+	}
+
+	config.token = d.Get("token").(string)
+	config.sessionLogin = d.Get("session_login").(bool)
+	if config.token == "" && (config.user == "" || config.password == "") {
+		return nil, diag.Errorf("Either token, or both user and password, must be set to authenticate with ATHENA")
+	}
+
+	config.caCert = d.Get("ca_cert").(string)
+	config.clientCert = d.Get("client_cert").(string)
+	config.clientKey = d.Get("client_key").(string)
+	config.tlsServerName = d.Get("tls_server_name").(string)
+	if v, ok := tlsVersions[d.Get("tls_min_version").(string)]; ok {
+		config.tlsMinVersion = v
+	}
+
+	if v := d.Get("job_poll_interval").(int); v > 0 {
+		config.jobPollInterval = time.Duration(v) * time.Second
+	}
+	if v := d.Get("job_poll_max_interval").(int); v > 0 {
+		config.jobPollMaxInterval = time.Duration(v) * time.Second
+	}
+	if v := d.Get("job_poll_backoff").(float64); v >= 1 {
+		config.jobPollBackoff = v
+	}
+	if v := d.Get("retry_max_attempts").(int); v > 0 {
+		config.retryMaxAttempts = v
+	}
+	if v := d.Get("retry_wait_min").(int); v > 0 {
+		config.retryWaitMin = time.Duration(v) * time.Second
+	}
+	if v := d.Get("retry_wait_max").(int); v > 0 {
+		config.retryWaitMax = time.Duration(v) * time.Second
+	}
+
+	if v := d.Get("request_timeout").(int); v > 0 {
+		config.requestTimeout = time.Duration(v) * time.Second
+	}
+	if v := d.Get("proxy_url").(string); v != "" {
+		proxyURL, err := url.Parse(v)
+		if err != nil {
+			return nil, diag.Errorf("Invalid proxy_url %q: %s", v, err)
+		}
+		config.proxyURL = proxyURL
+	}
+	for _, v := range d.Get("no_proxy").([]interface{}) {
+		config.noProxy = append(config.noProxy, v.(string))
+	}
+
+	if !config.verifySSL {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "SSL certificate verification is disabled",
+			Detail:   "verify_ssl is false, so the ATHENA server certificate will not be validated. Do not use this setting in production.",
+		})
+	}
+
+	apiClient, err := config.NewAthenaApiClient()
+	if err != nil {
+		return nil, append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Failed to configure the ATHENA client",
+			Detail:   err.Error(),
+		})
+	}
+
+	if workspace := d.Get("workspace").(string); workspace != "" {
+		if err := apiClient.SetDefaultWorkspace(ctx, workspace); err != nil {
+			return nil, append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("Workspace %q configured on the provider does not exist", workspace),
+				Detail:   err.Error(),
+			})
+		}
+	}
+
+	return apiClient, diags
 }
 
 func NewConfig(scheme string, address string, port string, user string, password string, verifySSL bool) Config {
+	if scheme == "" {
+		scheme = "https"
+	}
+
+	host := address
+	if port != "" {
+		host = net.JoinHostPort(address, port)
+	}
+
 	return Config{
-		scheme:    scheme,
-		address:   address,
-		port:      port,
+		endpoint:  &url.URL{Scheme: scheme, Host: host, Path: "/"},
 		user:      user,
 		password:  password,
 		verifySSL: verifySSL,
+
+		tlsMinVersion:      tls.VersionTLS12,
+		jobPollInterval:    5 * time.Second,
+		jobPollMaxInterval: 60 * time.Second,
+		jobPollBackoff:     1.5,
+		retryMaxAttempts:   4,
+		retryWaitMin:       1 * time.Second,
+		retryWaitMax:       30 * time.Second,
+		requestTimeout:     60 * time.Second,
+	}
+}
+
+// parseEndpoint parses the endpoint argument into a base URL that ends in a
+// slash, so API paths resolve beneath any path prefix.
+func parseEndpoint(rawURL string) (*url.URL, error) {
+	endpoint, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return nil, errors.New("endpoint must be an http or https URL")
+	}
+	if endpoint.Host == "" {
+		return nil, errors.New("endpoint must include a host")
 	}
+
+	endpoint.RawQuery = ""
+	endpoint.Fragment = ""
+	if !strings.HasSuffix(endpoint.Path, "/") {
+		endpoint.Path += "/"
+	}
+	return endpoint, nil
+}
+
+// diagFromError converts an API client error into diagnostics. Job failures are
+// reported with the job summary and one line per job error message.
+func diagFromError(summary string, err error) diag.Diagnostics {
+	if err == nil {
+		return nil
+	}
+
+	var jobErr *JobError
+	if errors.As(err, &jobErr) {
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  summary + ": " + jobErr.Summary(),
+			Detail:   strings.Join(jobErr.Messages(), "\n"),
+		}}
+	}
+
+	switch {
+	case IsUnauthorized(err):
+		summary += ": ATHENA rejected the provider credentials"
+	case IsForbidden(err):
+		summary += ": ATHENA user is not permitted to perform this operation"
+	case IsConflict(err):
+		summary += ": the object was modified or already exists in ATHENA"
+	}
+
+	return diag.Diagnostics{{
+		Severity: diag.Error,
+		Summary:  summary,
+		Detail:   err.Error(),
+	}}
+}
+
+// templatePropertiesSchema returns the schema of the template_properties
+// argument a managed object is created with. ATHENA doesn't return the
+// properties, so an imported object has none in state and would be replaced
+// unless the configuration ignores them.
+func templatePropertiesSchema(description string) *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeMap,
+		Optional:    true,
+		ForceNew:    true,
+		Description: description + " Not returned by ATHENA: after an import, add template_properties to lifecycle.ignore_changes to keep the object.",
+	}
+}
+
+// resolvePolicyName returns a CustomizeDiffFunc that plans policy_id as the ID
+// of the T in policyResourceType named by policy_name. Only policy_id is read
+// back from ATHENA, so it, rather than the name, decides whether a different
+// policy forces a new resource.
+func resolvePolicyName[T namedEntity](policyResourceType string) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+		if !d.NewValueKnown("policy_name") {
+			return nil
+		}
+		policyName, ok := d.GetOk("policy_name")
+		if !ok {
+			return nil
+		}
+
+		policy, err := FindByName[T](ctx, meta.(*AthenaAPIClient), policyResourceType, policyName.(string))
+		if err != nil {
+			return errors.WithMessage(err, "Cannot resolve policy_name: "+policyName.(string))
+		}
+
+		policyID := (*policy).entityID()
+		if oldPolicyID, _ := d.GetChange("policy_id"); oldPolicyID.(int) == policyID {
+			return nil
+		}
+
+		if err := d.SetNew("policy_id", policyID); err != nil {
+			return errors.WithMessage(err, "Cannot set policy_id")
+		}
+		if d.Id() == "" {
+			return nil
+		}
+		return d.ForceNew("policy_id")
+	}
+}
+
+// suppressCaseDiff ignores a diff that only changes letter case.
+func suppressCaseDiff(k, old, new string, d *schema.ResourceData) bool {
+	return strings.EqualFold(old, new)
+}
+
+// suppressEquivalentDNSNameDiff ignores differences in letter case and a
+// trailing dot between two DNS names.
+func suppressEquivalentDNSNameDiff(k, old, new string, d *schema.ResourceData) bool {
+	return normalizeDNSName(old) == normalizeDNSName(new)
+}
+
+// hashDNSName hashes a DNS name set element so that names differing only in
+// letter case or a trailing dot are the same element.
+func hashDNSName(v interface{}) int {
+	return schema.HashString(normalizeDNSName(v.(string)))
+}
+
+func normalizeDNSName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
+
+// suppressEquivalentIPDiff ignores differences in how the same IP address is
+// written, such as a compressed and an expanded IPv6 address.
+func suppressEquivalentIPDiff(k, old, new string, d *schema.ResourceData) bool {
+	oldIP, newIP := net.ParseIP(old), net.ParseIP(new)
+	return oldIP != nil && oldIP.Equal(newIP)
 }
@@ -0,0 +1,98 @@
+// Copyright 2020 CloudBolt Software
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package athena
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/go-cty/cty/msgpack"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// providerConfig encodes a provider configuration with every attribute not in
+// values left null, as Terraform sends it over the plugin protocol.
+func providerConfig(t *testing.T, values map[string]cty.Value) *tfprotov5.DynamicValue {
+	configType := schema.InternalMap(Provider().Schema).CoreConfigSchema().ImpliedType()
+
+	attrs := map[string]cty.Value{}
+	for name, attrType := range configType.AttributeTypes() {
+		if v, ok := values[name]; ok {
+			attrs[name] = v
+		} else {
+			attrs[name] = cty.NullVal(attrType)
+		}
+	}
+
+	b, err := msgpack.Marshal(cty.ObjectVal(attrs), configType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &tfprotov5.DynamicValue{MsgPack: b}
+}
+
+func diagnosticErrors(diags []*tfprotov5.Diagnostic) []string {
+	var errs []string
+	for _, d := range diags {
+		if d.Severity == tfprotov5.DiagnosticSeverityError {
+			errs = append(errs, d.Summary+": "+d.Detail)
+		}
+	}
+	return errs
+}
+
+func TestProviderPrepareConfigWithOnlyEndpoint(t *testing.T) {
+	for _, k := range []string{"ATHENA_SCHEME", "ATHENA_ADDRESS", "ATHENA_PORT"} {
+		t.Setenv(k, "")
+	}
+
+	server := schema.NewGRPCProviderServer(Provider())
+	res, err := server.PrepareProviderConfig(context.Background(), &tfprotov5.PrepareProviderConfigRequest{
+		Config: providerConfig(t, map[string]cty.Value{
+			"endpoint": cty.StringVal("https://gw.example.com/athena/"),
+			"token":    cty.StringVal("secret"),
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if errs := diagnosticErrors(res.Diagnostics); len(errs) > 0 {
+		t.Errorf("expected endpoint alone to be valid, got: %s", strings.Join(errs, "; "))
+	}
+}
+
+func TestProviderConfigureRejectsEndpointWithAddress(t *testing.T) {
+	server := schema.NewGRPCProviderServer(Provider())
+	res, err := server.ConfigureProvider(context.Background(), &tfprotov5.ConfigureProviderRequest{
+		TerraformVersion: "1.5.0",
+		Config: providerConfig(t, map[string]cty.Value{
+			"endpoint": cty.StringVal("https://gw.example.com/athena/"),
+			"address":  cty.StringVal("athena.example.com"),
+			"token":    cty.StringVal("secret"),
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := diagnosticErrors(res.Diagnostics)
+	if len(errs) != 1 || !strings.Contains(errs[0], "endpoint conflicts with address") {
+		t.Errorf("expected an endpoint/address conflict, got: %v", errs)
+	}
+}
+
+func TestNewConfigDefaultsToHTTPS(t *testing.T) {
+	config := NewConfig("", "athena.example.com", "8443", "admin", "secret", true)
+
+	if got := config.endpoint.String(); got != "https://athena.example.com:8443/" {
+		t.Errorf("expected https endpoint, got %s", got)
+	}
+}
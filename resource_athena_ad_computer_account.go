@@ -0,0 +1,253 @@
+// Copyright 2020 CloudBolt Software
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package athena
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/pkg/errors"
+)
+
+const ADComputerAccountStateBuild = "build"
+const ADComputerAccountStateFinal = "final"
+
+func resourceADComputerAccount() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceADComputerAccountCreate,
+		ReadContext:   resourceADComputerAccountRead,
+		UpdateContext: resourceADComputerAccountUpdate,
+		DeleteContext: resourceADComputerAccountDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			// The AD policy may change the letter case of the name; the name
+			// as created is exposed as computer_name.
+			"name": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				DiffSuppressFunc: suppressCaseDiff,
+			},
+			"policy_id": {
+				Type:     schema.TypeInt,
+				Required: true,
+				ForceNew: true,
+			},
+			"workspace_url": {
+				Type:     schema.TypeString,
+				Computed: true,
+				Optional: true,
+				ForceNew: true,
+			},
+			"template_properties": templatePropertiesSchema("Properties used to create the computer account."),
+			"state": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.StringInSlice([]string{ADComputerAccountStateBuild, ADComputerAccountStateFinal}, false),
+			},
+			"computer_name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"build_ou": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"final_ou": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"ou": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"distinguished_name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"security_groups": {
+				Type: schema.TypeList,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Computed: true,
+			},
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+	}
+}
+
+func bindADComputerAccountResource(d *schema.ResourceData, computerAccount *MicrosoftADComputerAccount) error {
+	log.Println("athena.bindADComputerAccountResource")
+
+	// Keep the configured name; only an import has none yet.
+	if d.Get("name").(string) == "" {
+		if err := d.Set("name", computerAccount.Name); err != nil {
+			return errors.WithMessage(err, "Cannot set name: "+computerAccount.Name)
+		}
+	}
+
+	if err := d.Set("computer_name", computerAccount.Name); err != nil {
+		return errors.WithMessage(err, "Cannot set computer_name: "+computerAccount.Name)
+	}
+
+	if err := d.Set("state", computerAccount.State); err != nil {
+		return errors.WithMessage(err, "Cannot set state: "+computerAccount.State)
+	}
+
+	if err := d.Set("build_ou", computerAccount.BuildOU); err != nil {
+		return errors.WithMessage(err, "Cannot set build_ou: "+computerAccount.BuildOU)
+	}
+
+	if err := d.Set("final_ou", computerAccount.FinalOU); err != nil {
+		return errors.WithMessage(err, "Cannot set final_ou: "+computerAccount.FinalOU)
+	}
+
+	ou := computerAccount.BuildOU
+	if computerAccount.State == ADComputerAccountStateFinal || ou == "" {
+		ou = computerAccount.FinalOU
+	}
+	if err := d.Set("ou", ou); err != nil {
+		return errors.WithMessage(err, "Cannot set ou: "+ou)
+	}
+
+	distinguishedName := "CN=" + computerAccount.Name
+	if ou != "" {
+		distinguishedName += "," + ou
+	}
+	if err := d.Set("distinguished_name", distinguishedName); err != nil {
+		return errors.WithMessage(err, "Cannot set distinguished_name: "+distinguishedName)
+	}
+
+	if err := d.Set("security_groups", computerAccount.SecurityGroups); err != nil {
+		return errors.WithMessage(err, "Cannot set security_groups")
+	}
+
+	if computerAccount.Links != nil {
+		if err := d.Set("workspace_url", computerAccount.Links.Workspace.Href); err != nil {
+			return errors.WithMessage(err, "Cannot set workspace: "+computerAccount.Links.Workspace.Href)
+		}
+
+		if err := d.Set("policy_id", idFromHref(computerAccount.Links.Policy.Href)); err != nil {
+			return errors.WithMessage(err, "Cannot set policy")
+		}
+	}
+
+	return nil
+}
+
+func resourceADComputerAccountCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("athena.resourceADComputerAccountCreate")
+
+	apiClient := m.(*AthenaAPIClient)
+
+	newComputerAccount := MicrosoftADComputerAccount{
+		Name:               d.Get("name").(string),
+		PolicyID:           d.Get("policy_id").(int),
+		WorkspaceURL:       d.Get("workspace_url").(string),
+		TemplateProperties: d.Get("template_properties").(map[string]interface{}),
+	}
+
+	computerAccount, err := apiClient.CreateMicrosoftADComputerAccount(ctx, &newComputerAccount)
+	if err != nil {
+		return diagFromError("Failed to create AD computer account", err)
+	}
+	d.SetId(strconv.Itoa(computerAccount.ID))
+
+	// Accounts are staged in the build OU; move straight to the final OU
+	// when that is what the configuration asks for.
+	if d.Get("state").(string) == ADComputerAccountStateFinal && computerAccount.State != ADComputerAccountStateFinal {
+		computerAccount, err = apiClient.MoveMicrosoftADComputerAccount(ctx, computerAccount.ID, ADComputerAccountStateFinal)
+		if err != nil {
+			return diagFromError("Failed to move AD computer account to the final OU", err)
+		}
+	}
+
+	return diag.FromErr(bindADComputerAccountResource(d, computerAccount))
+}
+
+func resourceADComputerAccountRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("athena.resourceADComputerAccountRead")
+
+	apiClient := m.(*AthenaAPIClient)
+
+	id := d.Id()
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	computerAccount, err := apiClient.GetMicrosoftADComputerAccount(ctx, intID)
+	if err != nil {
+		if IsNotFound(err) {
+			log.Printf("athena.resourceADComputerAccountRead: AD computer account %s not found, removing from state", id)
+			d.SetId("")
+			return nil
+		}
+		return diagFromError("Failed to read AD computer account", err)
+	}
+
+	return diag.FromErr(bindADComputerAccountResource(d, computerAccount))
+}
+
+func resourceADComputerAccountUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("athena.resourceADComputerAccountUpdate")
+
+	if !d.HasChange("state") {
+		return nil
+	}
+
+	apiClient := m.(*AthenaAPIClient)
+
+	id := d.Id()
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	computerAccount, err := apiClient.MoveMicrosoftADComputerAccount(ctx, intID, d.Get("state").(string))
+	if err != nil {
+		return diagFromError("Failed to move AD computer account", err)
+	}
+
+	return diag.FromErr(bindADComputerAccountResource(d, computerAccount))
+}
+
+func resourceADComputerAccountDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("athena.resourceADComputerAccountDelete")
+
+	apiClient := m.(*AthenaAPIClient)
+
+	id := d.Id()
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := apiClient.DeleteMicrosoftADComputerAccount(ctx, intID); err != nil {
+		if IsNotFound(err) {
+			log.Printf("athena.resourceADComputerAccountDelete: AD computer account %s already deleted", id)
+			return nil
+		}
+		return diagFromError("Failed to delete AD computer account", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2020 CloudBolt Software
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package athena
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestResourceADComputerAccountRead(t *testing.T) {
+	accountPath := "/" + ApiVersion + "/" + ApiNamespace + "/" + MicrosoftADComputerAccountResourceType + "/5/"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != accountPath {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"id":5,"name":"WEB01","state":"final",
+			"buildOu":"OU=Build,DC=example,DC=com","finalOu":"OU=Servers,DC=example,DC=com",
+			"securityGroups":["CN=Web,DC=example,DC=com"],
+			"_links":{"policy":{"href":"/api/v3/onefuse/microsoftADPolicies/3/"},"workspace":{"href":"/api/v3/onefuse/workspaces/2/"}}}`)
+	}))
+	defer server.Close()
+
+	cases := []struct {
+		configuredName string
+		expectedName   string
+	}{
+		// The configured name is kept when ATHENA changed its case.
+		{"web01", "web01"},
+		// An import has no name yet and takes the one ATHENA returns.
+		{"", "WEB01"},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, resourceADComputerAccount().Schema, map[string]interface{}{"name": c.configuredName})
+		d.SetId("5")
+
+		if diags := resourceADComputerAccountRead(context.Background(), d, newTestAPIClient(t, server)); diags.HasError() {
+			t.Fatalf("unexpected diagnostics %#v", diags)
+		}
+
+		expected := map[string]interface{}{
+			"name":               c.expectedName,
+			"computer_name":      "WEB01",
+			"state":              ADComputerAccountStateFinal,
+			"ou":                 "OU=Servers,DC=example,DC=com",
+			"distinguished_name": "CN=WEB01,OU=Servers,DC=example,DC=com",
+			"policy_id":          3,
+			"workspace_url":      "/api/v3/onefuse/workspaces/2/",
+			"security_groups.0":  "CN=Web,DC=example,DC=com",
+		}
+		for k, v := range expected {
+			if got := d.Get(k); got != v {
+				t.Errorf("name %q: expected %s to be %v, got %v", c.configuredName, k, v, got)
+			}
+		}
+	}
+}
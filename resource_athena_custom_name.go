@@ -0,0 +1,158 @@
+// Copyright 2020 CloudBolt Software
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package athena
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+func resourceCustomName() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceCustomNameCreate,
+		ReadContext:   resourceCustomNameRead,
+		DeleteContext: resourceCustomNameDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"policy_id": {
+				Type:     schema.TypeInt,
+				Required: true,
+				ForceNew: true,
+			},
+			"workspace_url": {
+				Type:     schema.TypeString,
+				Computed: true,
+				Optional: true,
+				ForceNew: true,
+			},
+			"template_properties": templatePropertiesSchema("Properties used to generate the name."),
+			"name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"dns_suffix": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"fqdn": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+	}
+}
+
+func bindCustomNameResource(d *schema.ResourceData, customName *CustomName) error {
+	log.Println("athena.bindCustomNameResource")
+
+	if err := d.Set("name", customName.Name); err != nil {
+		return errors.WithMessage(err, "Cannot set name: "+customName.Name)
+	}
+
+	if err := d.Set("dns_suffix", customName.DnsSuffix); err != nil {
+		return errors.WithMessage(err, "Cannot set dns_suffix: "+customName.DnsSuffix)
+	}
+
+	fqdn := customName.Name
+	if customName.DnsSuffix != "" {
+		fqdn = customName.Name + "." + customName.DnsSuffix
+	}
+	if err := d.Set("fqdn", fqdn); err != nil {
+		return errors.WithMessage(err, "Cannot set fqdn: "+fqdn)
+	}
+
+	if customName.Links != nil {
+		if err := d.Set("workspace_url", customName.Links.Workspace.Href); err != nil {
+			return errors.WithMessage(err, "Cannot set workspace: "+customName.Links.Workspace.Href)
+		}
+
+		if err := d.Set("policy_id", idFromHref(customName.Links.Policy.Href)); err != nil {
+			return errors.WithMessage(err, "Cannot set policy")
+		}
+	}
+
+	return nil
+}
+
+func resourceCustomNameCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("athena.resourceCustomNameCreate")
+
+	apiClient := m.(*AthenaAPIClient)
+
+	newCustomName := CustomName{
+		PolicyID:           d.Get("policy_id").(int),
+		WorkspaceURL:       d.Get("workspace_url").(string),
+		TemplateProperties: d.Get("template_properties").(map[string]interface{}),
+	}
+
+	customName, err := apiClient.CreateCustomName(ctx, &newCustomName)
+	if err != nil {
+		return diagFromError("Failed to create custom name", err)
+	}
+	d.SetId(strconv.Itoa(customName.Id))
+
+	return diag.FromErr(bindCustomNameResource(d, customName))
+}
+
+func resourceCustomNameRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("athena.resourceCustomNameRead")
+
+	apiClient := m.(*AthenaAPIClient)
+
+	id := d.Id()
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	customName, err := apiClient.GetCustomName(ctx, intID)
+	if err != nil {
+		if IsNotFound(err) {
+			log.Printf("athena.resourceCustomNameRead: Custom name %s not found, removing from state", id)
+			d.SetId("")
+			return nil
+		}
+		return diagFromError("Failed to read custom name", err)
+	}
+
+	return diag.FromErr(bindCustomNameResource(d, customName))
+}
+
+func resourceCustomNameDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("athena.resourceCustomNameDelete")
+
+	apiClient := m.(*AthenaAPIClient)
+
+	id := d.Id()
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := apiClient.DeleteCustomName(ctx, intID); err != nil {
+		if IsNotFound(err) {
+			log.Printf("athena.resourceCustomNameDelete: Custom name %s already deleted", id)
+			return nil
+		}
+		return diagFromError("Failed to delete custom name", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2020 CloudBolt Software
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package athena
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestResourceCustomNameRead(t *testing.T) {
+	customNamePath := "/" + ApiVersion + "/" + ApiNamespace + "/" + CustomNameResourceType + "/7/"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != customNamePath {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"id":7,"name":"web01","dnsSuffix":"example.com","_links":{
+			"policy":{"href":"/api/v3/onefuse/namingPolicies/3/"},
+			"workspace":{"href":"/api/v3/onefuse/workspaces/2/"}
+		}}`)
+	}))
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, resourceCustomName().Schema, map[string]interface{}{})
+	d.SetId("7")
+
+	if diags := resourceCustomNameRead(context.Background(), d, newTestAPIClient(t, server)); diags.HasError() {
+		t.Fatalf("unexpected diagnostics %#v", diags)
+	}
+
+	expected := map[string]interface{}{
+		"name":          "web01",
+		"dns_suffix":    "example.com",
+		"fqdn":          "web01.example.com",
+		"policy_id":     3,
+		"workspace_url": "/api/v3/onefuse/workspaces/2/",
+	}
+	for k, v := range expected {
+		if got := d.Get(k); got != v {
+			t.Errorf("expected %s to be %v, got %v", k, v, got)
+		}
+	}
+}
+
+func TestResourceCustomNameReadRemovesDeleted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, resourceCustomName().Schema, map[string]interface{}{})
+	d.SetId("7")
+
+	if diags := resourceCustomNameRead(context.Background(), d, newTestAPIClient(t, server)); diags.HasError() {
+		t.Fatalf("unexpected diagnostics %#v", diags)
+	}
+	if d.Id() != "" {
+		t.Errorf("expected the custom name to be removed from state, got ID %q", d.Id())
+	}
+}
+
+func TestResourceCustomNameTemplatePropertiesForceReplacement(t *testing.T) {
+	state := &terraform.InstanceState{
+		ID: "7",
+		Attributes: map[string]string{
+			"id":                      "7",
+			"policy_id":               "3",
+			"workspace_url":           "/api/v3/onefuse/workspaces/2/",
+			"name":                    "web01",
+			"template_properties.%":   "1",
+			"template_properties.env": "dev",
+		},
+	}
+	withoutProperties := state.DeepCopy()
+	delete(withoutProperties.Attributes, "template_properties.%")
+	delete(withoutProperties.Attributes, "template_properties.env")
+
+	cases := []struct {
+		name        string
+		state       *terraform.InstanceState
+		properties  map[string]interface{}
+		requiresNew bool
+	}{
+		{"unchanged", state, map[string]interface{}{"env": "dev"}, false},
+		{"changed", state, map[string]interface{}{"env": "prod"}, true},
+		{"gained", withoutProperties, map[string]interface{}{"env": "prod"}, true},
+	}
+
+	for _, c := range cases {
+		config := terraform.NewResourceConfigRaw(map[string]interface{}{
+			"policy_id":           3,
+			"template_properties": c.properties,
+		})
+
+		diff, err := resourceCustomName().Diff(context.Background(), c.state, config, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if requiresNew := diff != nil && diff.RequiresNew(); requiresNew != c.requiresNew {
+			t.Errorf("%s: expected replacement %t, got %#v", c.name, c.requiresNew, diff)
+		}
+	}
+}
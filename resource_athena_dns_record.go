@@ -0,0 +1,236 @@
+// Copyright 2020 CloudBolt Software
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package athena
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/pkg/errors"
+)
+
+func resourceDNSReservation() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceDNSReservationCreate,
+		ReadContext:   resourceDNSReservationRead,
+		DeleteContext: resourceDNSReservationDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				DiffSuppressFunc: suppressEquivalentDNSNameDiff,
+			},
+			"value": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				ValidateFunc:     validation.IsIPAddress,
+				DiffSuppressFunc: suppressEquivalentIPDiff,
+			},
+			"zones": {
+				Type: schema.TypeSet,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Set:      hashDNSName,
+				Required: true,
+				ForceNew: true,
+			},
+			"policy_id": {
+				Type:     schema.TypeInt,
+				Required: true,
+				ForceNew: true,
+			},
+			"workspace_url": {
+				Type:     schema.TypeString,
+				Computed: true,
+				Optional: true,
+				ForceNew: true,
+			},
+			"template_properties": templatePropertiesSchema("Properties used to create the records."),
+			"records": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"value": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"a_records": {
+				Type: schema.TypeList,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Computed: true,
+			},
+			"ptr_records": {
+				Type: schema.TypeList,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Computed: true,
+			},
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+	}
+}
+
+func bindDNSReservationResource(d *schema.ResourceData, dnsReservation *DNSReservation) error {
+	log.Println("athena.bindDNSReservationResource")
+
+	if err := d.Set("name", dnsReservation.Name); err != nil {
+		return errors.WithMessage(err, "Cannot set name: "+dnsReservation.Name)
+	}
+
+	if err := d.Set("value", dnsReservation.Value); err != nil {
+		return errors.WithMessage(err, "Cannot set value: "+dnsReservation.Value)
+	}
+
+	if err := d.Set("zones", dnsReservation.Zones); err != nil {
+		return errors.WithMessage(err, "Cannot set zones")
+	}
+
+	records := []map[string]interface{}{}
+	aRecords := []string{}
+	ptrRecords := []string{}
+	for _, record := range dnsReservation.Records {
+		records = append(records, map[string]interface{}{
+			"type":  record.Type,
+			"name":  record.Name,
+			"value": record.Value,
+		})
+		switch record.Type {
+		case "A":
+			aRecords = append(aRecords, record.Name)
+		case "PTR":
+			ptrRecords = append(ptrRecords, record.Name)
+		}
+	}
+
+	if err := d.Set("records", records); err != nil {
+		return errors.WithMessage(err, "Cannot set records")
+	}
+
+	if err := d.Set("a_records", aRecords); err != nil {
+		return errors.WithMessage(err, "Cannot set a_records")
+	}
+
+	if err := d.Set("ptr_records", ptrRecords); err != nil {
+		return errors.WithMessage(err, "Cannot set ptr_records")
+	}
+
+	if dnsReservation.Links != nil {
+		if err := d.Set("workspace_url", dnsReservation.Links.Workspace.Href); err != nil {
+			return errors.WithMessage(err, "Cannot set workspace: "+dnsReservation.Links.Workspace.Href)
+		}
+
+		if err := d.Set("policy_id", idFromHref(dnsReservation.Links.Policy.Href)); err != nil {
+			return errors.WithMessage(err, "Cannot set policy")
+		}
+	}
+
+	return nil
+}
+
+func resourceDNSReservationCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("athena.resourceDNSReservationCreate")
+
+	apiClient := m.(*AthenaAPIClient)
+
+	var zones []string
+	for _, zone := range d.Get("zones").(*schema.Set).List() {
+		zones = append(zones, zone.(string))
+	}
+
+	newDNSReservation := DNSReservation{
+		Name:               d.Get("name").(string),
+		Value:              d.Get("value").(string),
+		Zones:              zones,
+		PolicyID:           d.Get("policy_id").(int),
+		WorkspaceURL:       d.Get("workspace_url").(string),
+		TemplateProperties: d.Get("template_properties").(map[string]interface{}),
+	}
+
+	dnsReservation, err := apiClient.CreateDNSReservation(ctx, &newDNSReservation)
+	if err != nil {
+		return diagFromError("Failed to create DNS record", err)
+	}
+	d.SetId(strconv.Itoa(dnsReservation.ID))
+
+	return diag.FromErr(bindDNSReservationResource(d, dnsReservation))
+}
+
+func resourceDNSReservationRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("athena.resourceDNSReservationRead")
+
+	apiClient := m.(*AthenaAPIClient)
+
+	id := d.Id()
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	dnsReservation, err := apiClient.GetDNSReservation(ctx, intID)
+	if err != nil {
+		if IsNotFound(err) {
+			log.Printf("athena.resourceDNSReservationRead: DNS record %s not found, removing from state", id)
+			d.SetId("")
+			return nil
+		}
+		return diagFromError("Failed to read DNS record", err)
+	}
+
+	return diag.FromErr(bindDNSReservationResource(d, dnsReservation))
+}
+
+func resourceDNSReservationDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("athena.resourceDNSReservationDelete")
+
+	apiClient := m.(*AthenaAPIClient)
+
+	id := d.Id()
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := apiClient.DeleteDNSReservation(ctx, intID); err != nil {
+		if IsNotFound(err) {
+			log.Printf("athena.resourceDNSReservationDelete: DNS record %s already deleted", id)
+			return nil
+		}
+		return diagFromError("Failed to delete DNS record", err)
+	}
+
+	return nil
+}
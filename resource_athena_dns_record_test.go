@@ -0,0 +1,75 @@
+// Copyright 2020 CloudBolt Software
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package athena
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestResourceDNSReservationReadDetectsDrift(t *testing.T) {
+	reservationPath := "/" + ApiVersion + "/" + ApiNamespace + "/" + DNSReservationResourceType + "/8/"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != reservationPath {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		// The reverse zone was removed outside Terraform.
+		fmt.Fprint(w, `{"id":8,"name":"WEB01.example.com.","value":"2001:0db8:0000:0000:0000:0000:0000:0001",
+			"zones":["Example.com."],
+			"records":[{"type":"A","name":"web01.example.com","value":"2001:db8::1"}],
+			"_links":{"policy":{"href":"/api/v3/onefuse/dnsPolicies/3/"},"workspace":{"href":"/api/v3/onefuse/workspaces/2/"}}}`)
+	}))
+	defer server.Close()
+
+	config := map[string]interface{}{
+		"name":      "web01.example.com",
+		"value":     "2001:db8::1",
+		"zones":     []interface{}{"example.com", "db8.arpa"},
+		"policy_id": 3,
+	}
+	d := schema.TestResourceDataRaw(t, resourceDNSReservation().Schema, config)
+	d.SetId("8")
+
+	if diags := resourceDNSReservationRead(context.Background(), d, newTestAPIClient(t, server)); diags.HasError() {
+		t.Fatalf("unexpected diagnostics %#v", diags)
+	}
+
+	if got := d.Get("name").(string); got != "WEB01.example.com." {
+		t.Errorf("expected the name returned by ATHENA, got %q", got)
+	}
+	if got := d.Get("zones").(*schema.Set).List(); len(got) != 1 || got[0] != "Example.com." {
+		t.Errorf("expected only the zone returned by ATHENA, got %v", got)
+	}
+	if got := d.Get("a_records").([]interface{}); len(got) != 1 || got[0] != "web01.example.com" {
+		t.Errorf("unexpected a_records %v", got)
+	}
+
+	diff, err := resourceDNSReservation().Diff(context.Background(), d.State(), terraform.NewResourceConfigRaw(config), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff == nil || !diff.RequiresNew() {
+		t.Errorf("expected the missing zone to force replacement, got %#v", diff)
+	}
+
+	config["zones"] = []interface{}{"example.com"}
+	diff, err = resourceDNSReservation().Diff(context.Background(), d.State(), terraform.NewResourceConfigRaw(config), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff != nil && diff.RequiresNew() {
+		t.Errorf("expected equivalent name, value and zones not to force replacement, got %#v", diff.Attributes)
+	}
+}
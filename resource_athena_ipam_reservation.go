@@ -1,21 +1,26 @@
 package athena
 
 import (
+	"context"
 	"log"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/pkg/errors"
 )
 
 func resourceIPAMReservation() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceIPAMReservationCreate,
-		Read:   resourceIPAMReservationRead,
-		Update: resourceIPAMReservationUpdate,
-		Delete: resourceIPAMReservationDelete,
+		CreateContext: resourceIPAMReservationCreate,
+		ReadContext:   resourceIPAMReservationRead,
+		UpdateContext: resourceIPAMReservationUpdate,
+		DeleteContext: resourceIPAMReservationDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceIPAMReservationImport,
+		},
 		Schema: map[string]*schema.Schema{
 			"hostname": {
 				Type:     schema.TypeString,
@@ -102,6 +107,8 @@ func resourceIPAMReservation() *schema.Resource {
 		},
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
 			Delete: schema.DefaultTimeout(5 * time.Minute),
 		},
 	}
@@ -154,17 +161,14 @@ func bindIPAMReservationResource(d *schema.ResourceData, ipamRecord *IPAMReserva
 		return errors.WithMessage(err, "Cannot set DNSSuffix: "+ipamRecord.DNSSuffix)
 	}
 
-	ipamPolicyURLSplit := strings.Split(ipamRecord.Links.Policy.Href, "/")
-	ipamPolicyID := ipamPolicyURLSplit[len(ipamPolicyURLSplit)-2]
-	ipamPolicyIDInt, _ := strconv.Atoi(ipamPolicyID)
-	if err := d.Set("policy_id", ipamPolicyIDInt); err != nil {
+	if err := d.Set("policy_id", idFromHref(ipamRecord.Links.Policy.Href)); err != nil {
 		return errors.WithMessage(err, "Cannot set policy")
 	}
 
 	return nil
 }
 
-func resourceIPAMReservationCreate(d *schema.ResourceData, m interface{}) error {
+func resourceIPAMReservationCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	log.Println("athena.resourceIPAMReservationCreate")
 
 	var ipam_Suffixes []string
@@ -172,7 +176,7 @@ func resourceIPAMReservationCreate(d *schema.ResourceData, m interface{}) error
 		ipam_Suffixes = append(ipam_Suffixes, group.(string))
 	}
 
-	config := m.(Config)
+	apiClient := m.(*AthenaAPIClient)
 
 	newIPAMRecord := IPAMReservation{
 		Hostname:           d.Get("hostname").(string),
@@ -190,35 +194,40 @@ func resourceIPAMReservationCreate(d *schema.ResourceData, m interface{}) error
 		TemplateProperties: d.Get("template_properties").(map[string]interface{}),
 	}
 
-	ipamRecord, err := config.NewAthenaApiClient().CreateIPAMReservation(&newIPAMRecord)
+	ipamRecord, err := apiClient.CreateIPAMReservation(ctx, &newIPAMRecord)
 	if err != nil {
-		return err
+		return diagFromError("Failed to create IPAM reservation", err)
 	}
 	d.SetId(strconv.Itoa(ipamRecord.ID))
 
-	return bindIPAMReservationResource(d, ipamRecord)
+	return diag.FromErr(bindIPAMReservationResource(d, ipamRecord))
 }
 
-func resourceIPAMReservationRead(d *schema.ResourceData, m interface{}) error {
+func resourceIPAMReservationRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	log.Println("athena.resourceIPAMReservationRead")
 
-	config := m.(Config)
+	apiClient := m.(*AthenaAPIClient)
 
 	id := d.Id()
 	intID, err := strconv.Atoi(id)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	ipamRecord, err := config.NewAthenaApiClient().GetIPAMReservation(intID)
+	ipamRecord, err := apiClient.GetIPAMReservation(ctx, intID)
 	if err != nil {
-		return err
+		if IsNotFound(err) {
+			log.Printf("athena.resourceIPAMReservationRead: IPAM reservation %s not found, removing from state", id)
+			d.SetId("")
+			return nil
+		}
+		return diagFromError("Failed to read IPAM reservation", err)
 	}
 
-	return bindIPAMReservationResource(d, ipamRecord)
+	return diag.FromErr(bindIPAMReservationResource(d, ipamRecord))
 }
 
-func resourceIPAMReservationUpdate(d *schema.ResourceData, m interface{}) error {
+func resourceIPAMReservationUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	log.Println("athena.resourceIPAMReservationUpdate")
 
 	// Determine if a change is needed
@@ -247,7 +256,7 @@ func resourceIPAMReservationUpdate(d *schema.ResourceData, m interface{}) error
 	}
 
 	// Make the API call to update the computer account
-	config := m.(Config)
+	apiClient := m.(*AthenaAPIClient)
 
 	// Create the desired IPAM Reservation
 	id := d.Id()
@@ -269,27 +278,79 @@ func resourceIPAMReservationUpdate(d *schema.ResourceData, m interface{}) error
 
 	intID, err := strconv.Atoi(id)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
 	}
 
-	ipamRecord, err := config.NewAthenaApiClient().UpdateIPAMReservation(intID, &desiredIPAMRecord)
+	ipamRecord, err := apiClient.UpdateIPAMReservation(ctx, intID, &desiredIPAMRecord)
 	if err != nil {
-		return err
+		return diagFromError("Failed to update IPAM reservation", err)
 	}
 
-	return bindIPAMReservationResource(d, ipamRecord)
+	return diag.FromErr(bindIPAMReservationResource(d, ipamRecord))
 }
 
-func resourceIPAMReservationDelete(d *schema.ResourceData, m interface{}) error {
+func resourceIPAMReservationDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	log.Println("athena.resourceIPAMReservationDelete")
 
-	config := m.(Config)
+	apiClient := m.(*AthenaAPIClient)
 
 	id := d.Id()
 	intID, err := strconv.Atoi(id)
 	if err != nil {
-		return err
+		return diag.FromErr(err)
+	}
+
+	if err := apiClient.DeleteIPAMReservation(ctx, intID); err != nil {
+		if IsNotFound(err) {
+			log.Printf("athena.resourceIPAMReservationDelete: IPAM reservation %s already deleted", id)
+			return nil
+		}
+		return diagFromError("Failed to delete IPAM reservation", err)
+	}
+
+	return nil
+}
+
+// resourceIPAMReservationImport accepts either a reservation ID or
+// <policy_name>/<hostname>.
+func resourceIPAMReservationImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	log.Println("athena.resourceIPAMReservationImport")
+
+	apiClient := m.(*AthenaAPIClient)
+
+	importID := d.Id()
+
+	var ipamRecord *IPAMReservation
+	if intID, err := strconv.Atoi(importID); err == nil {
+		if ipamRecord, err = apiClient.GetIPAMReservation(ctx, intID); err != nil {
+			return nil, err
+		}
+	} else {
+		sep := strings.LastIndex(importID, "/")
+		if sep <= 0 || sep == len(importID)-1 {
+			return nil, errors.New("athena.resourceIPAMReservationImport: Import ID must be a reservation ID or <policy_name>/<hostname>, got: " + importID)
+		}
+		policyName, hostname := importID[:sep], importID[sep+1:]
+
+		ipamPolicy, err := apiClient.GetIPAMPolicyByName(ctx, policyName)
+		if err != nil {
+			return nil, err
+		}
+
+		if ipamRecord, err = apiClient.GetIPAMReservationByHostname(ctx, hostname, ipamPolicy.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	d.SetId(strconv.Itoa(ipamRecord.ID))
+
+	if err := d.Set("hostname", ipamRecord.Hostname); err != nil {
+		return nil, errors.WithMessage(err, "Cannot set hostname: "+ipamRecord.Hostname)
+	}
+
+	if err := bindIPAMReservationResource(d, ipamRecord); err != nil {
+		return nil, err
 	}
 
-	return config.NewAthenaApiClient().DeleteIPAMReservation(intID)
+	return []*schema.ResourceData{d}, nil
 }
@@ -0,0 +1,166 @@
+// Copyright 2020 CloudBolt Software
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package athena
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+func resourceModuleDeployment() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceModuleDeploymentCreate,
+		ReadContext:   resourceModuleDeploymentRead,
+		UpdateContext: resourceModuleDeploymentUpdate,
+		DeleteContext: resourceModuleDeploymentDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"policy_id": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"policy_id", "policy_name"},
+			},
+			// Resolved to policy_id while planning, which forces a new
+			// deployment when the named policy changes.
+			"policy_name": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"policy_id", "policy_name"},
+			},
+			"workspace_url": {
+				Type:     schema.TypeString,
+				Computed: true,
+				Optional: true,
+				ForceNew: true,
+			},
+			"template_properties": templatePropertiesSchema("Properties passed to the module policy."),
+			"name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"provisioning_job_results": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+		CustomizeDiff: resolvePolicyName[ModulePolicy](ModulePolicyResourceType),
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(30 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(30 * time.Minute),
+		},
+	}
+}
+
+func bindModuleDeploymentResource(d *schema.ResourceData, moduleDeployment *ModuleDeployment) error {
+	log.Println("athena.bindModuleDeploymentResource")
+
+	if err := d.Set("name", moduleDeployment.Name); err != nil {
+		return errors.WithMessage(err, "Cannot set name: "+moduleDeployment.Name)
+	}
+
+	provisioningJobResults := string(moduleDeployment.ProvisioningJobResults)
+	if err := d.Set("provisioning_job_results", provisioningJobResults); err != nil {
+		return errors.WithMessage(err, "Cannot set provisioning_job_results")
+	}
+
+	if moduleDeployment.Links != nil {
+		if err := d.Set("workspace_url", moduleDeployment.Links.Workspace.Href); err != nil {
+			return errors.WithMessage(err, "Cannot set workspace: "+moduleDeployment.Links.Workspace.Href)
+		}
+
+		if err := d.Set("policy_id", idFromHref(moduleDeployment.Links.Policy.Href)); err != nil {
+			return errors.WithMessage(err, "Cannot set policy")
+		}
+	}
+
+	return nil
+}
+
+func resourceModuleDeploymentCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("athena.resourceModuleDeploymentCreate")
+
+	apiClient := m.(*AthenaAPIClient)
+
+	newModuleDeployment := ModuleDeployment{
+		PolicyID:           d.Get("policy_id").(int),
+		WorkspaceURL:       d.Get("workspace_url").(string),
+		TemplateProperties: d.Get("template_properties").(map[string]interface{}),
+	}
+
+	moduleDeployment, err := apiClient.CreateModuleDeployment(ctx, &newModuleDeployment)
+	if err != nil {
+		return diagFromError("Failed to create module deployment", err)
+	}
+	d.SetId(strconv.Itoa(moduleDeployment.ID))
+
+	return diag.FromErr(bindModuleDeploymentResource(d, moduleDeployment))
+}
+
+func resourceModuleDeploymentRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("athena.resourceModuleDeploymentRead")
+
+	apiClient := m.(*AthenaAPIClient)
+
+	id := d.Id()
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	moduleDeployment, err := apiClient.GetModuleDeployment(ctx, intID)
+	if err != nil {
+		if IsNotFound(err) {
+			log.Printf("athena.resourceModuleDeploymentRead: Module deployment %s not found, removing from state", id)
+			d.SetId("")
+			return nil
+		}
+		return diagFromError("Failed to read module deployment", err)
+	}
+
+	return diag.FromErr(bindModuleDeploymentResource(d, moduleDeployment))
+}
+
+// resourceModuleDeploymentUpdate only runs when policy_name changes to a name
+// of the same policy, which needs no change in ATHENA.
+func resourceModuleDeploymentUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("athena.resourceModuleDeploymentUpdate")
+
+	return resourceModuleDeploymentRead(ctx, d, m)
+}
+
+func resourceModuleDeploymentDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("athena.resourceModuleDeploymentDelete")
+
+	apiClient := m.(*AthenaAPIClient)
+
+	id := d.Id()
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := apiClient.DeleteModuleDeployment(ctx, intID); err != nil {
+		if IsNotFound(err) {
+			log.Printf("athena.resourceModuleDeploymentDelete: Module deployment %s already deleted", id)
+			return nil
+		}
+		return diagFromError("Failed to delete module deployment", err)
+	}
+
+	return nil
+}
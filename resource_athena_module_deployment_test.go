@@ -0,0 +1,117 @@
+// Copyright 2020 CloudBolt Software
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package athena
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// newTestModuleServer serves module policies 4 (linux-hardening) and 5
+// (linux-baseline) and module deployment 12, which runs policy 4.
+func newTestModuleServer(t *testing.T) *httptest.Server {
+	policiesPath := "/" + ApiVersion + "/" + ApiNamespace + "/" + ModulePolicyResourceType + "/"
+	deploymentPath := "/" + ApiVersion + "/" + ApiNamespace + "/" + ModuleDepoloymentResourceType + "/12/"
+	policyIDs := map[string]int{"linux-hardening": 4, "linux-baseline": 5}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == policiesPath:
+			var name string
+			fmt.Sscanf(r.URL.Query().Get("filter"), "name.exact:%s", &name)
+			fmt.Fprintf(w, `{"_embedded":{"modulePolicies":[{"id":%d,"name":"%s"}]}}`, policyIDs[name], name)
+		case r.Method == "GET" && r.URL.Path == deploymentPath:
+			fmt.Fprint(w, `{"id":12,"name":"deployment-12","provisioningJobResults":[{"output":"ok"}],
+				"_links":{"policy":{"href":"/api/v3/onefuse/modulePolicies/4/"},"workspace":{"href":"/api/v3/onefuse/workspaces/2/"}}}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestResourceModuleDeploymentRead(t *testing.T) {
+	server := newTestModuleServer(t)
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, resourceModuleDeployment().Schema, map[string]interface{}{})
+	d.SetId("12")
+
+	if diags := resourceModuleDeploymentRead(context.Background(), d, newTestAPIClient(t, server)); diags.HasError() {
+		t.Fatalf("unexpected diagnostics %#v", diags)
+	}
+
+	expected := map[string]interface{}{
+		"name":                     "deployment-12",
+		"policy_id":                4,
+		"workspace_url":            "/api/v3/onefuse/workspaces/2/",
+		"provisioning_job_results": `[{"output":"ok"}]`,
+	}
+	for k, v := range expected {
+		if got := d.Get(k); got != v {
+			t.Errorf("expected %s to be %v, got %v", k, v, got)
+		}
+	}
+}
+
+func TestResourceModuleDeploymentResolvesPolicyName(t *testing.T) {
+	server := newTestModuleServer(t)
+	defer server.Close()
+	apiClient := newTestAPIClient(t, server)
+
+	imported := &terraform.InstanceState{
+		ID: "12",
+		Attributes: map[string]string{
+			"id":            "12",
+			"policy_id":     "4",
+			"workspace_url": "/api/v3/onefuse/workspaces/2/",
+			"name":          "deployment-12",
+		},
+	}
+	created := imported.DeepCopy()
+	created.Attributes["policy_name"] = "linux-hardening"
+
+	cases := []struct {
+		name             string
+		state            *terraform.InstanceState
+		policyName       string
+		expectedPolicyID string
+		requiresNew      bool
+	}{
+		// A create plans every ForceNew attribute as new.
+		{"create", nil, "linux-hardening", "4", true},
+		{"import", imported, "linux-hardening", "", false},
+		{"unchanged", created, "linux-hardening", "", false},
+		{"changed", created, "linux-baseline", "5", true},
+	}
+
+	for _, c := range cases {
+		config := terraform.NewResourceConfigRaw(map[string]interface{}{"policy_name": c.policyName})
+
+		diff, err := resourceModuleDeployment().Diff(context.Background(), c.state, config, apiClient)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if requiresNew := diff != nil && diff.RequiresNew(); requiresNew != c.requiresNew {
+			t.Errorf("%s: expected replacement %t, got %#v", c.name, c.requiresNew, diff)
+		}
+
+		var policyID string
+		if diff != nil && diff.Attributes["policy_id"] != nil {
+			policyID = diff.Attributes["policy_id"].New
+		}
+		if policyID != c.expectedPolicyID {
+			t.Errorf("%s: expected policy_id to be planned as %q, got %q", c.name, c.expectedPolicyID, policyID)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+// Copyright 2020 CloudBolt Software
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package athena
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+func resourceScriptingDeployment() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceScriptingDeploymentCreate,
+		ReadContext:   resourceScriptingDeploymentRead,
+		UpdateContext: resourceScriptingDeploymentUpdate,
+		DeleteContext: resourceScriptingDeploymentDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"policy_id": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"policy_id", "policy_name"},
+			},
+			// Resolved to policy_id while planning, like the module
+			// deployment's policy_name.
+			"policy_name": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"policy_id", "policy_name"},
+			},
+			"workspace_url": {
+				Type:     schema.TypeString,
+				Computed: true,
+				Optional: true,
+				ForceNew: true,
+			},
+			"template_properties": templatePropertiesSchema("Properties passed to the scripts."),
+			"hostname": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"provisioning_status": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"provisioning_stdout": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"provisioning_stderr": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"provisioning_exit_code": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+		},
+		CustomizeDiff: resolvePolicyName[ScriptingPolicy](ScriptingPolicyResourceType),
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(30 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(30 * time.Minute),
+		},
+	}
+}
+
+func bindScriptingDeploymentResource(d *schema.ResourceData, scriptingDeployment *ScriptingDeployment) error {
+	log.Println("athena.bindScriptingDeploymentResource")
+
+	if err := d.Set("hostname", scriptingDeployment.Hostname); err != nil {
+		return errors.WithMessage(err, "Cannot set hostname: "+scriptingDeployment.Hostname)
+	}
+
+	provisioningDetails := scriptingDeployment.ProvisioningDetails
+	if provisioningDetails == nil {
+		provisioningDetails = &ScriptingDetails{}
+	}
+
+	if err := d.Set("provisioning_status", provisioningDetails.Status); err != nil {
+		return errors.WithMessage(err, "Cannot set provisioning_status: "+provisioningDetails.Status)
+	}
+
+	if err := d.Set("provisioning_stdout", provisioningDetails.Stdout); err != nil {
+		return errors.WithMessage(err, "Cannot set provisioning_stdout")
+	}
+
+	if err := d.Set("provisioning_stderr", provisioningDetails.Stderr); err != nil {
+		return errors.WithMessage(err, "Cannot set provisioning_stderr")
+	}
+
+	if err := d.Set("provisioning_exit_code", provisioningDetails.ExitCode); err != nil {
+		return errors.WithMessage(err, "Cannot set provisioning_exit_code")
+	}
+
+	if scriptingDeployment.Links != nil {
+		if err := d.Set("workspace_url", scriptingDeployment.Links.Workspace.Href); err != nil {
+			return errors.WithMessage(err, "Cannot set workspace: "+scriptingDeployment.Links.Workspace.Href)
+		}
+
+		if err := d.Set("policy_id", idFromHref(scriptingDeployment.Links.Policy.Href)); err != nil {
+			return errors.WithMessage(err, "Cannot set policy")
+		}
+	}
+
+	return nil
+}
+
+func resourceScriptingDeploymentCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("athena.resourceScriptingDeploymentCreate")
+
+	apiClient := m.(*AthenaAPIClient)
+
+	newScriptingDeployment := ScriptingDeployment{
+		PolicyID:           d.Get("policy_id").(int),
+		WorkspaceURL:       d.Get("workspace_url").(string),
+		TemplateProperties: d.Get("template_properties").(map[string]interface{}),
+	}
+
+	scriptingDeployment, err := apiClient.CreateScriptingDeployment(ctx, &newScriptingDeployment)
+	if err != nil && scriptingDeployment == nil {
+		return diagFromError("Failed to create scripting deployment", err)
+	}
+	d.SetId(strconv.Itoa(scriptingDeployment.ID))
+
+	diags := diag.FromErr(bindScriptingDeploymentResource(d, scriptingDeployment))
+	if err != nil {
+		// With the ID set, Terraform keeps the failed deployment as tainted
+		// and runs the deprovisioning script when replacing it.
+		diags = append(withScriptOutput(diagFromError("Failed to create scripting deployment", err), scriptingDeployment.ProvisioningDetails), diags...)
+	}
+	return diags
+}
+
+func resourceScriptingDeploymentRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("athena.resourceScriptingDeploymentRead")
+
+	apiClient := m.(*AthenaAPIClient)
+
+	id := d.Id()
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	scriptingDeployment, err := apiClient.GetScriptingDeployment(ctx, intID)
+	if err != nil {
+		if IsNotFound(err) {
+			log.Printf("athena.resourceScriptingDeploymentRead: Scripting deployment %s not found, removing from state", id)
+			d.SetId("")
+			return nil
+		}
+		return diagFromError("Failed to read scripting deployment", err)
+	}
+
+	return diag.FromErr(bindScriptingDeploymentResource(d, scriptingDeployment))
+}
+
+// resourceScriptingDeploymentUpdate only runs when policy_name changes to a
+// name of the same policy, which needs no change in ATHENA.
+func resourceScriptingDeploymentUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("athena.resourceScriptingDeploymentUpdate")
+
+	return resourceScriptingDeploymentRead(ctx, d, m)
+}
+
+func resourceScriptingDeploymentDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("athena.resourceScriptingDeploymentDelete")
+
+	apiClient := m.(*AthenaAPIClient)
+
+	id := d.Id()
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	deprovisioningDetails, err := apiClient.DeleteScriptingDeployment(ctx, intID)
+	if err != nil {
+		if IsNotFound(err) {
+			log.Printf("athena.resourceScriptingDeploymentDelete: Scripting deployment %s already deleted", id)
+			return nil
+		}
+		return withScriptOutput(diagFromError("Failed to delete scripting deployment", err), deprovisioningDetails)
+	}
+
+	// The resource leaves state once destroyed, so the deprovisioning output
+	// is reported as a warning.
+	if deprovisioningDetails != nil {
+		return diag.Diagnostics{{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("Deprovisioning script finished with status %q and exit code %d", deprovisioningDetails.Status, deprovisioningDetails.ExitCode),
+			Detail:   scriptOutput(deprovisioningDetails),
+		}}
+	}
+
+	return nil
+}
+
+// withScriptOutput adds the status and output of a failed script to the
+// detail of the error diagnostic in diags.
+func withScriptOutput(diags diag.Diagnostics, details *ScriptingDetails) diag.Diagnostics {
+	if details == nil {
+		return diags
+	}
+
+	detail := fmt.Sprintf("Script finished with status %q and exit code %d\n%s", details.Status, details.ExitCode, scriptOutput(details))
+	if diags[0].Detail != "" {
+		detail = diags[0].Detail + "\n\n" + detail
+	}
+	diags[0].Detail = detail
+	return diags
+}
+
+func scriptOutput(details *ScriptingDetails) string {
+	return fmt.Sprintf("stdout:\n%s\nstderr:\n%s", details.Stdout, details.Stderr)
+}
@@ -0,0 +1,125 @@
+// Copyright 2020 CloudBolt Software
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package athena
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestResourceScriptingDeploymentDeleteWarnsWithOutput(t *testing.T) {
+	deploymentPath := "/" + ApiVersion + "/" + ApiNamespace + "/" + ScriptingDeploymentResourceType + "/5/"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "DELETE" && r.URL.Path == deploymentPath:
+			w.WriteHeader(http.StatusAccepted)
+			fmt.Fprint(w, `{"id":21}`)
+		case strings.HasSuffix(r.URL.Path, "/"+JobStatusResourceType+"/21/"):
+			fmt.Fprintf(w, `{"id":21,"jobState":"Successful","_links":{"managedObject":{"href":"%s"}}}`, deploymentPath)
+		case r.Method == "GET" && r.URL.Path == deploymentPath:
+			fmt.Fprint(w, `{"id":5,"deprovisioningDetails":{"status":"Successful","stdout":"removed agent","stderr":"","exitCode":0}}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, resourceScriptingDeployment().Schema, map[string]interface{}{"policy_id": 3})
+	d.SetId("5")
+
+	diags := resourceScriptingDeploymentDelete(context.Background(), d, newTestAPIClient(t, server))
+	if len(diags) != 1 || diags[0].Severity != diag.Warning {
+		t.Fatalf("expected one warning, got %#v", diags)
+	}
+	if !strings.Contains(diags[0].Summary, `status "Successful" and exit code 0`) {
+		t.Errorf("unexpected summary %q", diags[0].Summary)
+	}
+	if !strings.Contains(diags[0].Detail, "removed agent") {
+		t.Errorf("expected stdout in the detail, got %q", diags[0].Detail)
+	}
+}
+
+func TestResourceScriptingDeploymentDeleteReportsFailedScript(t *testing.T) {
+	deploymentPath := "/" + ApiVersion + "/" + ApiNamespace + "/" + ScriptingDeploymentResourceType + "/5/"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "DELETE" && r.URL.Path == deploymentPath:
+			w.WriteHeader(http.StatusAccepted)
+			fmt.Fprint(w, `{"id":21}`)
+		case strings.HasSuffix(r.URL.Path, "/"+JobStatusResourceType+"/21/"):
+			fmt.Fprintf(w, `{"id":21,"jobState":"Failed","jobType":"Destroy Scripting Deployment",
+				"errorDetails":{"errors":[{"message":"Script exited with code 3"}]},
+				"_links":{"managedObject":{"href":"%s"}}}`, deploymentPath)
+		case r.Method == "GET" && r.URL.Path == deploymentPath:
+			fmt.Fprint(w, `{"id":5,"deprovisioningDetails":{"status":"Failed","stdout":"stopping agent","stderr":"agent busy","exitCode":3}}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, resourceScriptingDeployment().Schema, map[string]interface{}{"policy_id": 3})
+	d.SetId("5")
+
+	diags := resourceScriptingDeploymentDelete(context.Background(), d, newTestAPIClient(t, server))
+	if len(diags) != 1 || diags[0].Severity != diag.Error {
+		t.Fatalf("expected one error, got %#v", diags)
+	}
+	for _, expected := range []string{"Script exited with code 3", `status "Failed" and exit code 3`, "stopping agent", "agent busy"} {
+		if !strings.Contains(diags[0].Detail, expected) {
+			t.Errorf("expected %q in the detail, got %q", expected, diags[0].Detail)
+		}
+	}
+}
+
+func TestResourceScriptingDeploymentCreateKeepsFailedDeployment(t *testing.T) {
+	collectionPath := "/" + ApiVersion + "/" + ApiNamespace + "/" + ScriptingDeploymentResourceType + "/"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == collectionPath:
+			w.WriteHeader(http.StatusAccepted)
+			fmt.Fprint(w, `{"id":20}`)
+		case strings.HasSuffix(r.URL.Path, "/"+JobStatusResourceType+"/20/"):
+			fmt.Fprintf(w, `{"id":20,"jobState":"Failed","jobType":"Create Scripting Deployment","_links":{"managedObject":{"href":"%s6/"}}}`, collectionPath)
+		case r.Method == "GET" && r.URL.Path == collectionPath+"6/":
+			fmt.Fprint(w, `{"id":6,"hostname":"web01","provisioningDetails":{"status":"Failed","stdout":"","stderr":"no such package","exitCode":1},
+				"_links":{"policy":{"href":"/api/v3/onefuse/scriptingPolicies/3/"},"workspace":{"href":"/api/v3/onefuse/workspaces/2/"}}}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	d := schema.TestResourceDataRaw(t, resourceScriptingDeployment().Schema, map[string]interface{}{
+		"policy_id":     3,
+		"workspace_url": "/api/v3/onefuse/workspaces/2/",
+	})
+
+	diags := resourceScriptingDeploymentCreate(context.Background(), d, newTestAPIClient(t, server))
+	if len(diags) != 1 || diags[0].Severity != diag.Error {
+		t.Fatalf("expected one error, got %#v", diags)
+	}
+	if !strings.Contains(diags[0].Detail, "no such package") {
+		t.Errorf("expected stderr in the detail, got %q", diags[0].Detail)
+	}
+	if d.Id() != "6" {
+		t.Errorf("expected the failed deployment to be kept in state, got ID %q", d.Id())
+	}
+	if got := d.Get("provisioning_exit_code").(int); got != 1 {
+		t.Errorf("expected provisioning_exit_code 1, got %d", got)
+	}
+}
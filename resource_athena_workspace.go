@@ -0,0 +1,156 @@
+// Copyright 2020 CloudBolt Software
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package athena
+
+import (
+	"context"
+	"log"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+func resourceWorkspace() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceWorkspaceCreate,
+		ReadContext:   resourceWorkspaceRead,
+		UpdateContext: resourceWorkspaceUpdate,
+		DeleteContext: resourceWorkspaceDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"url": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func bindWorkspaceResource(d *schema.ResourceData, workspace *Workspace) error {
+	log.Println("athena.bindWorkspaceResource")
+
+	if err := d.Set("name", workspace.Name); err != nil {
+		return errors.WithMessage(err, "Cannot set name: "+workspace.Name)
+	}
+
+	if err := d.Set("description", workspace.Description); err != nil {
+		return errors.WithMessage(err, "Cannot set description: "+workspace.Description)
+	}
+
+	if workspace.Links != nil {
+		if err := d.Set("url", workspace.Links.Self.Href); err != nil {
+			return errors.WithMessage(err, "Cannot set url: "+workspace.Links.Self.Href)
+		}
+	}
+
+	return nil
+}
+
+func resourceWorkspaceCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("athena.resourceWorkspaceCreate")
+
+	apiClient := m.(*AthenaAPIClient)
+
+	newWorkspace := Workspace{
+		Name:        d.Get("name").(string),
+		Description: d.Get("description").(string),
+	}
+
+	workspace, err := apiClient.CreateWorkspace(ctx, &newWorkspace)
+	if err != nil {
+		return diagFromError("Failed to create workspace", err)
+	}
+	d.SetId(strconv.Itoa(workspace.ID))
+
+	return diag.FromErr(bindWorkspaceResource(d, workspace))
+}
+
+func resourceWorkspaceRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("athena.resourceWorkspaceRead")
+
+	apiClient := m.(*AthenaAPIClient)
+
+	id := d.Id()
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	workspace, err := apiClient.GetWorkspace(ctx, intID)
+	if err != nil {
+		if IsNotFound(err) {
+			log.Printf("athena.resourceWorkspaceRead: Workspace %s not found, removing from state", id)
+			d.SetId("")
+			return nil
+		}
+		return diagFromError("Failed to read workspace", err)
+	}
+
+	return diag.FromErr(bindWorkspaceResource(d, workspace))
+}
+
+func resourceWorkspaceUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("athena.resourceWorkspaceUpdate")
+
+	if !d.HasChanges("name", "description") {
+		return nil
+	}
+
+	apiClient := m.(*AthenaAPIClient)
+
+	id := d.Id()
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	desiredWorkspace := Workspace{
+		Name:        d.Get("name").(string),
+		Description: d.Get("description").(string),
+	}
+
+	workspace, err := apiClient.UpdateWorkspace(ctx, intID, &desiredWorkspace)
+	if err != nil {
+		return diagFromError("Failed to update workspace", err)
+	}
+
+	return diag.FromErr(bindWorkspaceResource(d, workspace))
+}
+
+func resourceWorkspaceDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	log.Println("athena.resourceWorkspaceDelete")
+
+	apiClient := m.(*AthenaAPIClient)
+
+	id := d.Id()
+	intID, err := strconv.Atoi(id)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := apiClient.DeleteWorkspace(ctx, intID); err != nil {
+		if IsNotFound(err) {
+			log.Printf("athena.resourceWorkspaceDelete: Workspace %s already deleted", id)
+			return nil
+		}
+		return diagFromError("Failed to delete workspace", err)
+	}
+
+	return nil
+}
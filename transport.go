@@ -0,0 +1,430 @@
+// Copyright 2020 CloudBolt Software
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package athena
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// newHttpClient builds the long-lived client shared by every request an
+// AthenaAPIClient makes, so connections are pooled and kept alive.
+func newHttpClient(config *Config) (*http.Client, error) {
+	tlsConfig, err := newTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+
+	tr := &http.Transport{
+		Proxy:                 proxyFunc(config),
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: config.requestTimeout,
+		ExpectContinueTimeout: 1 * time.Second,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   10,
+		IdleConnTimeout:       90 * time.Second,
+	}
+
+	return &http.Client{Transport: newAuthTransport(newRetryTransport(tr, config), config)}, nil
+}
+
+// newTLSConfig builds the TLS settings for the ATHENA connection from the
+// provider's CA bundle, client certificate and verification options.
+func newTLSConfig(config *Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: !config.verifySSL,
+		ServerName:         config.tlsServerName,
+		MinVersion:         config.tlsMinVersion,
+	}
+
+	if config.caCert != "" {
+		caPEM, err := readPEM(config.caCert)
+		if err != nil {
+			return nil, errors.WithMessage(err, "athena.newTLSConfig: Failed to read ca_cert")
+		}
+
+		certPool, err := x509.SystemCertPool()
+		if err != nil || certPool == nil {
+			certPool = x509.NewCertPool()
+		}
+		if !certPool.AppendCertsFromPEM(caPEM) {
+			return nil, errors.New("athena.newTLSConfig: ca_cert does not contain any PEM encoded certificates")
+		}
+		tlsConfig.RootCAs = certPool
+	}
+
+	if config.clientCert != "" || config.clientKey != "" {
+		if config.clientCert == "" || config.clientKey == "" {
+			return nil, errors.New("athena.newTLSConfig: client_cert and client_key must be set together")
+		}
+
+		certPEM, err := readPEM(config.clientCert)
+		if err != nil {
+			return nil, errors.WithMessage(err, "athena.newTLSConfig: Failed to read client_cert")
+		}
+		keyPEM, err := readPEM(config.clientKey)
+		if err != nil {
+			return nil, errors.WithMessage(err, "athena.newTLSConfig: Failed to read client_key")
+		}
+
+		clientCert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, errors.WithMessage(err, "athena.newTLSConfig: Failed to load client certificate")
+		}
+		tlsConfig.Certificates = []tls.Certificate{clientCert}
+	}
+
+	return tlsConfig, nil
+}
+
+// readPEM returns value itself if it holds PEM content, otherwise the contents
+// of the file it names.
+func readPEM(value string) ([]byte, error) {
+	if strings.Contains(value, "-----BEGIN") {
+		return []byte(value), nil
+	}
+	return ioutil.ReadFile(value)
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// proxyFunc returns the explicitly configured proxy, bypassed for hosts matching
+// noProxy, or falls back to the HTTP(S)_PROXY and NO_PROXY environment variables.
+func proxyFunc(config *Config) func(*http.Request) (*url.URL, error) {
+	if config.proxyURL == nil {
+		return http.ProxyFromEnvironment
+	}
+
+	return func(req *http.Request) (*url.URL, error) {
+		if bypassProxy(req.URL.Hostname(), config.noProxy) {
+			return nil, nil
+		}
+		return config.proxyURL, nil
+	}
+}
+
+// bypassProxy reports whether host matches one of the no_proxy entries. An entry
+// may be "*", an exact host, a domain suffix such as ".example.com", or a CIDR.
+func bypassProxy(host string, noProxy []string) bool {
+	host = strings.ToLower(host)
+	ip := net.ParseIP(host)
+
+	for _, entry := range noProxy {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case entry == "":
+			continue
+		case entry == "*":
+			return true
+		case ip != nil && strings.Contains(entry, "/"):
+			if _, cidr, err := net.ParseCIDR(entry); err == nil && cidr.Contains(ip) {
+				return true
+			}
+		case host == strings.TrimPrefix(entry, "."):
+			return true
+		case strings.HasSuffix(host, "."+strings.TrimPrefix(entry, ".")):
+			return true
+		}
+	}
+	return false
+}
+
+// retryTransport retries requests that failed with a transient error: a
+// connection reset, or a 429, 502, 503 or 504 response.
+type retryTransport struct {
+	base        http.RoundTripper
+	maxAttempts int
+	waitMin     time.Duration
+	waitMax     time.Duration
+}
+
+func newRetryTransport(base http.RoundTripper, config *Config) http.RoundTripper {
+	if config.retryMaxAttempts <= 1 {
+		return base
+	}
+	return &retryTransport{
+		base:        base,
+		maxAttempts: config.retryMaxAttempts,
+		waitMin:     config.retryWaitMin,
+		waitMax:     config.retryWaitMax,
+	}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		res, err := t.base.RoundTrip(req)
+
+		if attempt >= t.maxAttempts || !shouldRetry(req, res, err) {
+			return res, err
+		}
+
+		if req.Body != nil {
+			if req.GetBody == nil {
+				return res, err
+			}
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return res, err
+			}
+			req.Body = body
+		}
+
+		wait := t.backoff(attempt, res)
+		if res != nil {
+			log.Printf("athena.retryTransport: %s %s returned %d, retrying in %s (attempt %d of %d)", req.Method, req.URL, res.StatusCode, wait, attempt+1, t.maxAttempts)
+			io.Copy(ioutil.Discard, res.Body)
+			res.Body.Close()
+		} else {
+			log.Printf("athena.retryTransport: %s %s failed with %v, retrying in %s (attempt %d of %d)", req.Method, req.URL, err, wait, attempt+1, t.maxAttempts)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// shouldRetry reports whether the request can safely be sent again. Responses
+// that mean the request was not processed (429, 503) are retried for every
+// method; gateway errors and connection resets only for idempotent methods.
+func shouldRetry(req *http.Request, res *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		return isIdempotent(req.Method) && errors.Is(err, syscall.ECONNRESET)
+	}
+
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return isIdempotent(req.Method)
+	}
+	return false
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// backoff returns the wait before the next attempt, preferring the server's
+// Retry-After header when present.
+func (t *retryTransport) backoff(attempt int, res *http.Response) time.Duration {
+	if res != nil {
+		if wait, ok := retryAfter(res.Header.Get("Retry-After")); ok {
+			if wait > t.waitMax {
+				return t.waitMax
+			}
+			return wait
+		}
+	}
+
+	wait := t.waitMin << uint(attempt-1)
+	if wait <= 0 || wait > t.waitMax {
+		wait = t.waitMax
+	}
+	return withJitter(wait)
+}
+
+// retryAfter parses a Retry-After header given either in seconds or as an HTTP date.
+func retryAfter(header string) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+// authTransport authenticates every request. A static token is sent as a bearer
+// token; with session login enabled, the user and password are exchanged for a
+// token once, which is cached and renewed when ATHENA responds 401. Otherwise
+// basic auth is used.
+type authTransport struct {
+	base   http.RoundTripper
+	config *Config
+
+	mu    sync.Mutex
+	token string
+}
+
+type sessionLoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type sessionLoginResponse struct {
+	Token string `json:"token"`
+}
+
+func newAuthTransport(base http.RoundTripper, config *Config) http.RoundTripper {
+	return &authTransport{
+		base:   base,
+		config: config,
+	}
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.currentToken(req)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := t.base.RoundTrip(t.authorize(req, token))
+	if err != nil || res.StatusCode != http.StatusUnauthorized || !t.usesSession() {
+		return res, err
+	}
+	if req.Body != nil && req.GetBody == nil {
+		return res, nil
+	}
+
+	// The session token has expired; log in again and replay the request once.
+	io.Copy(ioutil.Discard, res.Body)
+	res.Body.Close()
+	t.invalidate(token)
+
+	if token, err = t.currentToken(req); err != nil {
+		return nil, err
+	}
+
+	retryReq := t.authorize(req, token)
+	if req.GetBody != nil {
+		if retryReq.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	return t.base.RoundTrip(retryReq)
+}
+
+func (t *authTransport) usesSession() bool {
+	return t.config.token == "" && t.config.sessionLogin
+}
+
+// authorize returns a copy of req carrying the credentials, leaving the
+// caller's request untouched.
+func (t *authTransport) authorize(req *http.Request, token string) *http.Request {
+	authReq := req.Clone(req.Context())
+	if token != "" {
+		authReq.Header.Set("Authorization", "Bearer "+token)
+	} else {
+		authReq.SetBasicAuth(t.config.user, t.config.password)
+	}
+	return authReq
+}
+
+func (t *authTransport) currentToken(req *http.Request) (string, error) {
+	if t.config.token != "" {
+		return t.config.token, nil
+	}
+	if !t.config.sessionLogin {
+		return "", nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.token == "" {
+		token, err := t.login(req)
+		if err != nil {
+			return "", err
+		}
+		t.token = token
+	}
+	return t.token, nil
+}
+
+func (t *authTransport) invalidate(token string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.token == token {
+		t.token = ""
+	}
+}
+
+func (t *authTransport) login(req *http.Request) (string, error) {
+	log.Println("athena.authTransport: login")
+
+	url := collectionURL(t.config, SessionLoginResourceType)
+
+	jsonBytes, err := json.Marshal(sessionLoginRequest{Username: t.config.user, Password: t.config.password})
+	if err != nil {
+		return "", errors.WithMessage(err, "athena.authTransport: Failed to marshal login request to JSON")
+	}
+
+	loginReq, err := http.NewRequestWithContext(req.Context(), "POST", url, strings.NewReader(string(jsonBytes)))
+	if err != nil {
+		return "", errors.WithMessage(err, fmt.Sprintf("athena.authTransport: Unable to create request POST %s", url))
+	}
+	setHeaders(loginReq, t.config)
+
+	res, err := t.base.RoundTrip(loginReq)
+	if err != nil {
+		return "", errors.WithMessage(err, fmt.Sprintf("athena.authTransport: Failed to do request POST %s", url))
+	}
+
+	body, err := readResponse(res)
+	if err != nil {
+		return "", errors.WithMessage(err, "athena.authTransport: Login failed")
+	}
+	defer res.Body.Close()
+
+	loginResponse := sessionLoginResponse{}
+	if err = json.Unmarshal(body, &loginResponse); err != nil {
+		return "", errors.WithMessage(err, "athena.authTransport: Failed to unmarshal login response")
+	}
+	if loginResponse.Token == "" {
+		return "", errors.New("athena.authTransport: Login response did not contain a token")
+	}
+
+	return loginResponse.Token, nil
+}
@@ -0,0 +1,240 @@
+// Copyright 2020 CloudBolt Software
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package athena
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestRetryClient(maxAttempts int) *http.Client {
+	config := &Config{
+		retryMaxAttempts: maxAttempts,
+		retryWaitMin:     time.Millisecond,
+		retryWaitMax:     10 * time.Millisecond,
+	}
+	return &http.Client{Transport: newRetryTransport(http.DefaultTransport, config)}
+}
+
+func TestRetryTransportReplaysBodyAfter503(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest("POST", server.URL, strings.NewReader(`{"name":"a"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := newTestRetryClient(3).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("expected 200, got %d", res.StatusCode)
+	}
+	if len(bodies) != 2 {
+		t.Fatalf("expected 2 attempts, got %d", len(bodies))
+	}
+	for i, body := range bodies {
+		if body != `{"name":"a"}` {
+			t.Errorf("attempt %d sent body %q", i+1, body)
+		}
+	}
+}
+
+func TestRetryTransportDoesNotRetryPostOn502(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest("POST", server.URL, strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := newTestRetryClient(3).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusBadGateway {
+		t.Errorf("expected 502, got %d", res.StatusCode)
+	}
+	if attempts != 1 {
+		t.Errorf("expected 1 attempt, got %d", attempts)
+	}
+}
+
+func TestRetryTransportRetriesGetOn502(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	res, err := newTestRetryClient(3).Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	cases := []struct {
+		header string
+		wait   time.Duration
+		ok     bool
+	}{
+		{"", 0, false},
+		{"0", 0, true},
+		{"7", 7 * time.Second, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0, true},
+	}
+
+	for _, c := range cases {
+		wait, ok := retryAfter(c.header)
+		if wait != c.wait || ok != c.ok {
+			t.Errorf("retryAfter(%q) = %s, %t; expected %s, %t", c.header, wait, ok, c.wait, c.ok)
+		}
+	}
+
+	wait, ok := retryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	if !ok || wait <= 0 || wait > time.Minute {
+		t.Errorf("retryAfter(date in a minute) = %s, %t", wait, ok)
+	}
+}
+
+func TestRetryTransportBackoffCapsRetryAfter(t *testing.T) {
+	transport := &retryTransport{waitMin: time.Second, waitMax: 5 * time.Second}
+
+	res := &http.Response{Header: http.Header{}}
+	res.Header.Set("Retry-After", "2")
+	if wait := transport.backoff(1, res); wait != 2*time.Second {
+		t.Errorf("expected Retry-After of 2s to be honoured, got %s", wait)
+	}
+
+	res.Header.Set("Retry-After", "120")
+	if wait := transport.backoff(1, res); wait != 5*time.Second {
+		t.Errorf("expected Retry-After to be capped at 5s, got %s", wait)
+	}
+}
+
+func TestAuthTransportLogsInAgainOn401(t *testing.T) {
+	logins := 0
+	var authorizations []string
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/"+SessionLoginResourceType+"/") {
+			logins++
+			fmt.Fprintf(w, `{"token":"token-%d"}`, logins)
+			return
+		}
+
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		if r.Header.Get("Authorization") != "Bearer token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	endpoint, err := parseEndpoint(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &Config{endpoint: endpoint, user: "admin", password: "secret", sessionLogin: true}
+	client := &http.Client{Transport: newAuthTransport(http.DefaultTransport, config)}
+
+	req, err := http.NewRequest("POST", collectionURL(config, WorkspaceResourceType), strings.NewReader(`{"name":"a"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("expected 200, got %d", res.StatusCode)
+	}
+	if logins != 2 {
+		t.Errorf("expected 2 logins, got %d", logins)
+	}
+	expected := []string{"Bearer token-1", "Bearer token-2"}
+	if strings.Join(authorizations, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected authorizations %v, got %v", expected, authorizations)
+	}
+	for i, body := range bodies {
+		if body != `{"name":"a"}` {
+			t.Errorf("attempt %d sent body %q", i+1, body)
+		}
+	}
+}
+
+func TestAuthTransportDoesNotLogInAgainWithStaticToken(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("Authorization") != "Bearer static" {
+			t.Errorf("unexpected Authorization header %q", r.Header.Get("Authorization"))
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	endpoint, err := parseEndpoint(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &Config{endpoint: endpoint, token: "static", sessionLogin: true}
+	client := &http.Client{Transport: newAuthTransport(http.DefaultTransport, config)}
+
+	res, err := client.Get(collectionURL(config, WorkspaceResourceType))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", res.StatusCode)
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 request, got %d", requests)
+	}
+}